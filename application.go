@@ -102,8 +102,14 @@ type Application struct {
 	shutdownWait time.Duration
 	done         chan struct{} // closed when Shutdown() fully completes
 
-	services map[string]Service
-	wctxPool sync.Pool
+	services   map[string]Service
+	wctxPool   sync.Pool
+	websockets webSocketSet
+
+	validateInput bool
+	etags         bool
+	maxBodySize   int64
+	jsonOptions   JSONOptions
 }
 
 func (a *Application) Application() *Application { return a }
@@ -379,6 +385,10 @@ func NewApplication(options Options) *Application {
 		config:          viper.New(),
 		done:            make(chan struct{}),
 		shutdownWait:    options.ShutdownWait,
+		validateInput:   options.ValidateInput,
+		etags:           options.ETags,
+		maxBodySize:     options.MaxBodySize,
+		jsonOptions:     options.JSON,
 		routes: NewRouteRoot().
 			Get("/routes", renderRoutes).
 			Get("/status", renderStatus), // Default route mount point (can be extended with specific handlers).
@@ -0,0 +1,301 @@
+package golly
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+)
+
+var (
+	ErrBindFailed             = errors.New("invalid request parameters")
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+)
+
+// defaultMultipartMemory mirrors net/http's in-memory limit for ParseMultipartForm.
+const defaultMultipartMemory = 32 << 20
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	durationType        = reflect.TypeFor[time.Duration]()
+)
+
+// FieldError describes a single field that could not be bound or validated.
+type FieldError struct {
+	Field   string      `json:"field" yaml:"field"`
+	Source  ParamSource `json:"source" yaml:"source"`
+	Message string      `json:"message" yaml:"message"`
+}
+
+// Bind decodes the request into a new T. See WebContext.Bind.
+//
+// Example:
+//
+//	type ShowOrder struct {
+//	    ID     int64  `path:"id"`
+//	    Expand bool   `query:"expand"`
+//	    Tenant string `header:"X-Tenant"`
+//	}
+//
+//	in, err := golly.Bind[ShowOrder](wctx)
+func Bind[T any](wctx *WebContext) (T, error) {
+	var out T
+	err := wctx.Bind(&out)
+	return out, err
+}
+
+// Bind fills the struct pointed to by out from the request. The body is
+// decoded first (JSON, urlencoded or multipart form), then fields tagged
+// path:"name", query:"name" or header:"Name" are filled from their source
+// with type conversion. Tagged fields only ever come from their tag: any
+// value the body set for them is cleared.
+//
+// Field names and sources follow the same rules as RouteDoc.Input. Form
+// bodies use the form tag, falling back to the RouteDoc name.
+//
+// All conversion failures are collected and returned as a single 400
+// *Error whose "fields" extension lists a FieldError per field. With
+// Options.ValidateInput the bound struct is then checked with Validate.
+func (wctx *WebContext) Bind(out any) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("golly: Bind requires a non-nil struct pointer, got %T", out)
+	}
+
+	rv = rv.Elem()
+	fields := structFields(rv.Type(), ParamSourceInput)
+
+	var errs []FieldError
+	if err := wctx.bindBody(rv, fields, &errs); err != nil {
+		return err
+	}
+
+	var query url.Values
+	for _, f := range fields {
+		var values []string
+
+		switch f.source {
+		case ParamSourcePath:
+			if v, ok := wctx.URLParams().lookup(f.name); ok {
+				values = []string{v}
+			}
+		case ParamSourceQuery:
+			if query == nil {
+				query = wctx.request.URL.Query()
+			}
+			values = query[f.name]
+		case ParamSourceHeader:
+			values = wctx.request.Header.Values(f.name)
+		default:
+			continue
+		}
+
+		fv := rv.FieldByIndex(f.index)
+		fv.SetZero()
+
+		if err := setFieldValues(fv, values); err != nil {
+			errs = append(errs, FieldError{Field: f.name, Source: f.source, Message: err.Error()})
+		}
+	}
+
+	if len(errs) > 0 {
+		return NewError(http.StatusBadRequest, ErrBindFailed, map[string]any{"fields": errs})
+	}
+	return wctx.validateInput(out)
+}
+
+// bindBody decodes the request body into rv. Decode problems are recorded
+// in errs; an unsupported content type (415) or a failed body read, such
+// as an exceeded body limit (413), aborts binding.
+func (wctx *WebContext) bindBody(rv reflect.Value, fields []structField, errs *[]FieldError) error {
+	if wctx.request.Body == nil || wctx.request.Body == http.NoBody {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(wctx.request.Header.Get("Content-Type"))
+
+	switch {
+	case mediaType == "multipart/form-data":
+		if err := wctx.request.ParseMultipartForm(defaultMultipartMemory); err != nil {
+			var gerr *Error
+			if errors.As(err, &gerr) {
+				return gerr // body limit
+			}
+			*errs = append(*errs, FieldError{Source: ParamSourceInput, Message: err.Error()})
+			return nil
+		}
+		bindForm(rv, fields, wctx.request.MultipartForm.Value, errs)
+
+	case mediaType == "application/x-www-form-urlencoded":
+		body, err := wctx.Body()
+		if err != nil {
+			return err
+		}
+
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			*errs = append(*errs, FieldError{Source: ParamSourceInput, Message: err.Error()})
+			return nil
+		}
+		bindForm(rv, fields, values, errs)
+
+	case mediaType == "", mediaType == "application/json", strings.HasSuffix(mediaType, "+json"):
+		body, err := wctx.Body()
+		if err != nil {
+			return err
+		}
+		if len(body) == 0 {
+			return nil
+		}
+
+		if err := wctx.unmarshalJSON(body, rv.Addr().Interface()); err != nil {
+			fe := FieldError{Source: ParamSourceInput, Message: err.Error()}
+
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				fe.Field = typeErr.Field
+				fe.Message = "expected " + typeErr.Type.String()
+			}
+			*errs = append(*errs, fe)
+		}
+
+	default:
+		body, err := wctx.Body()
+		if err != nil {
+			return err
+		}
+		if len(body) == 0 {
+			return nil
+		}
+		return NewError(http.StatusUnsupportedMediaType, ErrUnsupportedContentType, map[string]any{"content_type": mediaType})
+	}
+
+	return nil
+}
+
+func bindForm(rv reflect.Value, fields []structField, values map[string][]string, errs *[]FieldError) {
+	for _, f := range fields {
+		if f.source != ParamSourceInput {
+			continue
+		}
+
+		name := f.name
+		if tag, _, _ := strings.Cut(f.field.Tag.Get("form"), ","); tag != "" {
+			name = tag
+		}
+
+		vals, ok := values[name]
+		if !ok {
+			continue
+		}
+
+		if err := setFieldValues(rv.FieldByIndex(f.index), vals); err != nil {
+			*errs = append(*errs, FieldError{Field: name, Source: ParamSourceInput, Message: err.Error()})
+		}
+	}
+}
+
+// setFieldValues converts values into v. Slices take every value, other
+// kinds take the first. No values leaves v untouched.
+func setFieldValues(v reflect.Value, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 && !implementsTextUnmarshaler(v.Type()) {
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, str := range values {
+			if err := setFieldValue(s.Index(i), str); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+
+	return setFieldValue(v, values[0])
+}
+
+// setFieldValue converts a single string into v.
+func setFieldValue(v reflect.Value, str string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setFieldValue(ptr.Elem(), str); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+
+	if implementsTextUnmarshaler(v.Type()) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(str)); err != nil {
+			return fmt.Errorf("invalid value %q: %w", str, err)
+		}
+		return nil
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", str)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(str)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", str)
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(str, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", str)
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(str, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", str)
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(str, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", str)
+		}
+		v.SetFloat(f)
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		v.SetBytes([]byte(str))
+
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func implementsTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
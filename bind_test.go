@@ -0,0 +1,187 @@
+package golly
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bindAudit struct {
+	Tenant string `header:"X-Tenant"`
+}
+
+type bindOrderInput struct {
+	bindAudit
+
+	ID      int64         `path:"id"`
+	Ref     UUID          `query:"ref"`
+	Page    *int          `query:"page"`
+	Tags    []string      `query:"tag"`
+	Timeout time.Duration `query:"timeout"`
+	Since   time.Time     `query:"since"`
+
+	Name     string  `json:"name" form:"title"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+// bindRequest routes req through a /orders/{id} route and returns what Bind produced.
+func bindRequest(t *testing.T, req *http.Request) (bindOrderInput, error) {
+	t.Helper()
+
+	var (
+		got bindOrderInput
+		err error
+	)
+
+	app := NewApplication(Options{})
+	app.routes.Add("/orders/{id}", func(wctx *WebContext) {
+		got, err = Bind[bindOrderInput](wctx)
+	}, ALL)
+
+	RouteRequest(app, req, httptest.NewRecorder())
+	return got, err
+}
+
+func TestBind(t *testing.T) {
+	t.Run("it should bind every source", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost,
+			"/orders/42?ref=0b4e1c2a-8f1d-4c0e-9a57-5a0e3c1d2b3f&page=3&tag=a&tag=b&timeout=1m30s&since=2026-01-02T03:04:05Z",
+			strings.NewReader(`{"name":"desk","quantity":2,"price":19.5}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant", "acme")
+
+		got, err := bindRequest(t, req)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(42), got.ID)
+		assert.Equal(t, "0b4e1c2a-8f1d-4c0e-9a57-5a0e3c1d2b3f", got.Ref.String())
+		require.NotNil(t, got.Page)
+		assert.Equal(t, 3, *got.Page)
+		assert.Equal(t, []string{"a", "b"}, got.Tags)
+		assert.Equal(t, 90*time.Second, got.Timeout)
+		assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), got.Since)
+		assert.Equal(t, "acme", got.Tenant)
+		assert.Equal(t, "desk", got.Name)
+		assert.Equal(t, 2, got.Quantity)
+		assert.Equal(t, 19.5, got.Price)
+	})
+
+	t.Run("it should not let the body set tagged fields", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/orders/7", strings.NewReader(`{"ID":99,"Tenant":"evil"}`))
+
+		got, err := bindRequest(t, req)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(7), got.ID)
+		assert.Empty(t, got.Tenant)
+	})
+
+	t.Run("it should bind urlencoded forms", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/orders/1", strings.NewReader("title=lamp&quantity=3&price=4.25"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		got, err := bindRequest(t, req)
+		require.NoError(t, err)
+
+		assert.Equal(t, "lamp", got.Name)
+		assert.Equal(t, 3, got.Quantity)
+		assert.Equal(t, 4.25, got.Price)
+	})
+
+	t.Run("it should bind multipart forms", func(t *testing.T) {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		require.NoError(t, mw.WriteField("title", "chair"))
+		require.NoError(t, mw.WriteField("quantity", "5"))
+		require.NoError(t, mw.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/orders/1", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+
+		got, err := bindRequest(t, req)
+		require.NoError(t, err)
+
+		assert.Equal(t, "chair", got.Name)
+		assert.Equal(t, 5, got.Quantity)
+	})
+
+	t.Run("it should list every failed field", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/orders/abc?ref=nope&page=x&timeout=soon",
+			strings.NewReader(`{"quantity":"two"}`))
+
+		_, err := bindRequest(t, req)
+		require.Error(t, err)
+
+		var gerr *Error
+		require.True(t, errors.As(err, &gerr))
+		assert.Equal(t, http.StatusBadRequest, gerr.Status())
+		assert.ErrorIs(t, err, ErrBindFailed)
+
+		fields, ok := gerr.Extensions()["fields"].([]FieldError)
+		require.True(t, ok)
+
+		got := map[string]ParamSource{}
+		for _, fe := range fields {
+			got[fe.Field] = fe.Source
+			assert.NotEmpty(t, fe.Message)
+		}
+
+		assert.Equal(t, map[string]ParamSource{
+			"quantity": ParamSourceInput,
+			"id":       ParamSourcePath,
+			"ref":      ParamSourceQuery,
+			"page":     ParamSourceQuery,
+			"timeout":  ParamSourceQuery,
+		}, got)
+	})
+
+	t.Run("it should reject unsupported content types", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/orders/1", strings.NewReader("<order/>"))
+		req.Header.Set("Content-Type", "application/xml")
+
+		_, err := bindRequest(t, req)
+
+		var gerr *Error
+		require.True(t, errors.As(err, &gerr))
+		assert.Equal(t, http.StatusUnsupportedMediaType, gerr.Status())
+	})
+
+	t.Run("it should require a struct pointer", func(t *testing.T) {
+		wctx := NewTestWebContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+		var n int
+		assert.Error(t, wctx.Bind(&n))
+		assert.Error(t, wctx.Bind(bindOrderInput{}))
+	})
+}
+
+func TestStructFieldsSources(t *testing.T) {
+	ps := Input(bindOrderInput{}).Params()
+
+	got := map[string]ParamSource{}
+	for _, p := range ps {
+		got[p.Name] = p.Source
+	}
+
+	assert.Equal(t, map[string]ParamSource{
+		"X-Tenant": ParamSourceHeader,
+		"id":       ParamSourcePath,
+		"ref":      ParamSourceQuery,
+		"page":     ParamSourceQuery,
+		"tag":      ParamSourceQuery,
+		"timeout":  ParamSourceQuery,
+		"since":    ParamSourceQuery,
+		"name":     ParamSourceInput,
+		"quantity": ParamSourceInput,
+		"price":    ParamSourceInput,
+	}, got)
+}
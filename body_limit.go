@@ -0,0 +1,191 @@
+package golly
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/segmentio/encoding/json"
+)
+
+var ErrJSONTooDeep = errors.New("json nesting too deep")
+
+// JSONOptions hardens JSON request decoding in Marshal, MarshalStream and
+// Bind.
+type JSONOptions struct {
+	// DisallowUnknownFields rejects objects with keys the target struct
+	// does not declare
+	DisallowUnknownFields bool
+
+	// MaxDepth rejects documents nesting objects and arrays deeper than
+	// this (0 means no limit)
+	MaxDepth int
+}
+
+// LimitBody caps the request body at n bytes. Reading past it fails with
+// a 413 *Error (ErrBodyTooLarge) from Body, Marshal, Bind and any
+// direct read of Request().Body, and asks the server to close the
+// connection. A declared Content-Length over n fails on the first read.
+// It replaces Options.MaxBodySize for this request, so it can raise the
+// limit as well as lower it (e.g. per route through a middleware).
+func (wctx *WebContext) LimitBody(n int64) {
+	body := wctx.request.Body
+	if body == nil || body == http.NoBody || n <= 0 {
+		return
+	}
+
+	lb, ok := body.(*maxBytesReader)
+	if !ok {
+		lb = &maxBytesReader{r: body, closer: body, header: wctx.writer.Header()}
+		wctx.request.Body = lb
+	}
+
+	lb.limit = n
+	lb.declared = wctx.request.ContentLength
+}
+
+// WithJSONOptions sets the JSON decoding options for this request,
+// replacing Options.JSON.
+func (wctx *WebContext) WithJSONOptions(opts JSONOptions) *WebContext {
+	wctx.jsonOptions = opts
+	return wctx
+}
+
+// applyRequestLimits seeds the per-request limits from the application.
+func (wctx *WebContext) applyRequestLimits() {
+	wctx.jsonOptions = JSONOptions{}
+	wctx.bodyErr = nil
+
+	a := wctx.ctx.application
+	if a == nil {
+		return
+	}
+
+	wctx.jsonOptions = a.jsonOptions
+	if a.maxBodySize > 0 {
+		wctx.LimitBody(a.maxBodySize)
+	}
+}
+
+// unmarshalJSON decodes b into out with the request's JSONOptions.
+func (wctx *WebContext) unmarshalJSON(b []byte, out any) error {
+	opts := wctx.jsonOptions
+
+	if opts.MaxDepth > 0 {
+		var s jsonDepthScanner
+		if err := s.scan(b, opts.MaxDepth); err != nil {
+			return err
+		}
+	}
+
+	if !opts.DisallowUnknownFields {
+		return json.Unmarshal(b, out)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(out)
+}
+
+// jsonDepthScanner tracks object/array nesting across chunks of a JSON
+// document, ignoring brackets inside strings.
+type jsonDepthScanner struct {
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+func (s *jsonDepthScanner) scan(p []byte, max int) error {
+	for _, c := range p {
+		switch {
+		case s.escaped:
+			s.escaped = false
+		case s.inString:
+			switch c {
+			case '\\':
+				s.escaped = true
+			case '"':
+				s.inString = false
+			}
+		case c == '"':
+			s.inString = true
+		case c == '{' || c == '[':
+			if s.depth++; s.depth > max {
+				return ErrJSONTooDeep
+			}
+		case c == '}' || c == ']':
+			s.depth--
+		}
+	}
+	return nil
+}
+
+// depthLimitedReader runs a jsonDepthScanner over a stream.
+type depthLimitedReader struct {
+	r       io.Reader
+	max     int
+	scanner jsonDepthScanner
+}
+
+func (d *depthLimitedReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	if scanErr := d.scanner.scan(p[:n], d.max); scanErr != nil {
+		// Withhold the chunk so the decoder cannot finish a value from it
+		return 0, scanErr
+	}
+	return n, err
+}
+
+// maxBytesReader fails with a 413 *Error once more than limit bytes are
+// read. With a header it also marks the response Connection: close, so
+// the server does not drain the rest of an oversized body.
+type maxBytesReader struct {
+	r      io.Reader
+	closer io.Closer
+	header http.Header
+
+	limit    int64
+	declared int64
+	read     int64
+	err      error
+}
+
+func (m *maxBytesReader) Read(p []byte) (int, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	if m.read == 0 && m.declared > m.limit {
+		return 0, m.fail()
+	}
+
+	// Read one byte past the limit to tell "exactly at" from "over"
+	if remaining := m.limit - m.read; int64(len(p)) > remaining+1 {
+		p = p[:remaining+1]
+	}
+
+	n, err := m.r.Read(p)
+	if m.read+int64(n) <= m.limit {
+		m.read += int64(n)
+		return n, err
+	}
+
+	n = int(m.limit - m.read)
+	m.read = m.limit
+	return n, m.fail()
+}
+
+func (m *maxBytesReader) fail() error {
+	m.err = NewError(http.StatusRequestEntityTooLarge, ErrBodyTooLarge, map[string]any{"limit": m.limit})
+	if m.header != nil {
+		m.header.Set("Connection", "close")
+	}
+	return m.err
+}
+
+func (m *maxBytesReader) Close() error {
+	if m.closer == nil {
+		return nil
+	}
+	return m.closer.Close()
+}
@@ -0,0 +1,196 @@
+package golly
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkedBody hides the length of a body, like a chunked upload.
+type chunkedBody struct{ io.Reader }
+
+func (chunkedBody) Close() error { return nil }
+
+func TestBodyLimit(t *testing.T) {
+	items := func(setup func(r *Route), handler HandlerFunc) func(r *Route) {
+		return func(r *Route) {
+			if setup != nil {
+				setup(r)
+			}
+			r.Post("/items", handler)
+		}
+	}
+
+	marshal := func(wctx *WebContext) {
+		var in map[string]any
+		if err := wctx.Marshal(&in); err != nil {
+			wctx.RenderError(err)
+			return
+		}
+		wctx.RenderJSON(in)
+	}
+
+	t.Run("it should accept bodies within MaxBodySize", func(t *testing.T) {
+		rec := serveTestApp(t, Options{MaxBodySize: 64}, items(nil, marshal), httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"a":1}`)))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"a":1}`, rec.Body.String())
+	})
+
+	t.Run("it should answer 413 past MaxBodySize", func(t *testing.T) {
+		body := `{"a":"` + strings.Repeat("x", 100) + `"}`
+
+		for name, r := range map[string]io.Reader{
+			"declared length": strings.NewReader(body),
+			"chunked":         chunkedBody{strings.NewReader(body)},
+		} {
+			rec := serveTestApp(t, Options{MaxBodySize: 64}, items(nil, marshal), httptest.NewRequest(http.MethodPost, "/items", r))
+
+			assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code, name)
+			assert.Equal(t, "close", rec.Header().Get("Connection"), name)
+			assert.Contains(t, rec.Body.String(), ErrBodyTooLarge.Error(), name)
+		}
+	})
+
+	t.Run("it should let a route raise or lower the limit", func(t *testing.T) {
+		body := `{"a":"` + strings.Repeat("x", 100) + `"}`
+		raise := func(r *Route) {
+			r.Use(func(next HandlerFunc) HandlerFunc {
+				return func(wctx *WebContext) {
+					wctx.LimitBody(1024)
+					next(wctx)
+				}
+			})
+		}
+
+		rec := serveTestApp(t, Options{MaxBodySize: 64}, items(raise, marshal), httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		lower := func(r *Route) {
+			r.Use(func(next HandlerFunc) HandlerFunc {
+				return func(wctx *WebContext) {
+					wctx.LimitBody(4)
+					next(wctx)
+				}
+			})
+		}
+
+		rec = serveTestApp(t, Options{}, items(lower, marshal), httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"a":1}`)))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("it should surface the limit from Bind and direct reads", func(t *testing.T) {
+		var bindErr, readErr error
+
+		bind := func(wctx *WebContext) {
+			var in struct{ Name string }
+			bindErr = wctx.Bind(&in)
+		}
+		read := func(wctx *WebContext) {
+			_, readErr = io.ReadAll(wctx.Request().Body)
+		}
+
+		for _, handler := range []HandlerFunc{bind, read} {
+			body := chunkedBody{strings.NewReader(`{"name":"widget"}`)}
+			serveTestApp(t, Options{MaxBodySize: 4}, items(nil, handler), httptest.NewRequest(http.MethodPost, "/items", body))
+		}
+
+		assert.ErrorIs(t, bindErr, ErrBodyTooLarge)
+		assert.ErrorIs(t, readErr, ErrBodyTooLarge)
+	})
+}
+
+// failingBody returns some data, then a read error.
+type failingBody struct{ done bool }
+
+func (f *failingBody) Read(p []byte) (int, error) {
+	if f.done {
+		return 0, io.ErrUnexpectedEOF
+	}
+	f.done = true
+	return copy(p, `{"a":`), nil
+}
+
+func (*failingBody) Close() error { return nil }
+
+func TestBodyReadErrors(t *testing.T) {
+	t.Run("it should surface truncated reads", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Body = &failingBody{}
+		wctx := NewTestWebContext(req, httptest.NewRecorder())
+
+		b, err := wctx.Body()
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.Equal(t, `{"a":`, string(b))
+
+		// Cached with the body
+		_, err = wctx.Body()
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+		var out map[string]any
+		assert.ErrorIs(t, wctx.Marshal(&out), io.ErrUnexpectedEOF)
+	})
+}
+
+func TestJSONOptions(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+
+	decode := func(opts JSONOptions, body string, stream bool) error {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		wctx := NewTestWebContext(req, httptest.NewRecorder()).WithJSONOptions(opts)
+
+		var out item
+		if stream {
+			return wctx.MarshalStream(&out)
+		}
+		return wctx.Marshal(&out)
+	}
+
+	for _, stream := range []bool{false, true} {
+		t.Run("it should allow unknown fields by default", func(t *testing.T) {
+			assert.NoError(t, decode(JSONOptions{}, `{"name":"a","extra":1}`, stream))
+		})
+
+		t.Run("it should reject unknown fields when strict", func(t *testing.T) {
+			err := decode(JSONOptions{DisallowUnknownFields: true}, `{"name":"a","extra":1}`, stream)
+			assert.ErrorContains(t, err, "extra")
+		})
+
+		t.Run("it should reject documents nested past MaxDepth", func(t *testing.T) {
+			deep := `{"name":"a","x":` + strings.Repeat("[", 10) + strings.Repeat("]", 10) + `}`
+
+			assert.ErrorIs(t, decode(JSONOptions{MaxDepth: 5}, deep, stream), ErrJSONTooDeep)
+			assert.NoError(t, decode(JSONOptions{MaxDepth: 11}, deep, stream))
+		})
+
+		t.Run("it should ignore brackets inside strings", func(t *testing.T) {
+			assert.NoError(t, decode(JSONOptions{MaxDepth: 1}, `{"name":"[[[{\"[["}`, stream))
+		})
+	}
+
+	t.Run("it should apply Options.JSON to Bind", func(t *testing.T) {
+		a, err := NewTestApplication(Options{JSON: JSONOptions{DisallowUnknownFields: true}})
+		require.NoError(t, err)
+
+		var bindErr error
+		a.routes.Post("/items", func(wctx *WebContext) {
+			var in item
+			bindErr = wctx.Bind(&in)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"a","extra":1}`))
+		RouteRequest(a, req, httptest.NewRecorder())
+
+		var gerr *Error
+		require.True(t, errors.As(bindErr, &gerr))
+		assert.Equal(t, http.StatusBadRequest, gerr.Status())
+	})
+}
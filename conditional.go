@@ -0,0 +1,207 @@
+package golly
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// SetETag sets the ETag of the response. tag is quoted when needed; weak
+// tags ("W/") promise semantic rather than byte-for-byte equivalence and
+// only satisfy If-None-Match.
+//
+// Example:
+//
+//	wctx.SetETag(strconv.Itoa(order.Version), false)
+//	if wctx.CheckPreconditions() {
+//	    return
+//	}
+func (wctx *WebContext) SetETag(tag string, weak bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		tag = `"` + tag + `"`
+	}
+	if weak {
+		tag = "W/" + tag
+	}
+	wctx.writer.Header().Set("ETag", tag)
+}
+
+// SetLastModified sets the Last-Modified header used for If-Modified-Since
+// and If-Unmodified-Since.
+func (wctx *WebContext) SetLastModified(t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	wctx.writer.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// CheckPreconditions evaluates the conditional request headers (RFC 9110
+// section 13.2.2) against the ETag and Last-Modified already set on the
+// response. A failed If-Match or If-Unmodified-Since, or an If-None-Match
+// hit on an unsafe method, is answered with a 412 *Error; a cache hit on
+// GET/HEAD is answered with 304. It returns true when a response was
+// written and the handler must stop.
+//
+// Call it before mutating state so If-Match gives optimistic concurrency;
+// Render calls it itself for GET and HEAD when validators are present.
+func (wctx *WebContext) CheckPreconditions() bool {
+	switch evaluatePreconditions(wctx.request, wctx.writer.Header()) {
+	case http.StatusNotModified:
+		writeNotModified(wctx.writer)
+		return true
+
+	case http.StatusPreconditionFailed:
+		RenderError(wctx, NewError(http.StatusPreconditionFailed, ErrPreconditionFailed))
+		return true
+	}
+	return false
+}
+
+// renderPreconditions tags body with a strong ETag when the application
+// opted in and no validator was set, then evaluates the conditional
+// headers. Only successful GET/HEAD responses whose status is not yet
+// committed take part.
+func (wctx *WebContext) renderPreconditions(body []byte) bool {
+	if m := wctx.request.Method; m != http.MethodGet && m != http.MethodHead {
+		return false
+	}
+
+	if w, ok := wctx.writer.(WrapResponseWriter); ok && w.Status() != 0 {
+		return false
+	}
+
+	h := wctx.writer.Header()
+	if h.Get("ETag") == "" && wctx.etagsEnabled() {
+		h.Set("ETag", strongETag(body))
+	}
+
+	if h.Get("ETag") == "" && h.Get("Last-Modified") == "" {
+		return false
+	}
+	return wctx.CheckPreconditions()
+}
+
+// conditionalRender reports whether Render must build the body of a HEAD
+// request to evaluate conditional headers.
+func (wctx *WebContext) conditionalRender() bool {
+	h := wctx.writer.Header()
+	return wctx.etagsEnabled() || h.Get("ETag") != "" || h.Get("Last-Modified") != ""
+}
+
+func (wctx *WebContext) etagsEnabled() bool {
+	if wctx.ctx == nil {
+		return false
+	}
+	a := wctx.ctx.Application()
+	return a != nil && a.etags
+}
+
+// strongETag hashes body into a quoted strong entity tag.
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// evaluatePreconditions returns 0 when the request may proceed, or the
+// status (304 or 412) to answer with.
+func evaluatePreconditions(r *http.Request, h http.Header) int {
+	etag := h.Get("ETag")
+	lastModified, _ := http.ParseTime(h.Get("Last-Modified"))
+	safe := r.Method == http.MethodGet || r.Method == http.MethodHead
+
+	if im := r.Header.Get("If-Match"); im != "" {
+		if !etagListMatches(im, etag, true) {
+			return http.StatusPreconditionFailed
+		}
+	} else if ius, err := http.ParseTime(r.Header.Get("If-Unmodified-Since")); err == nil && !lastModified.IsZero() {
+		if lastModified.Truncate(time.Second).After(ius) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etagListMatches(inm, etag, false) {
+			if safe {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if ims, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && safe && !lastModified.IsZero() {
+		if !lastModified.Truncate(time.Second).After(ims) {
+			return http.StatusNotModified
+		}
+	}
+
+	return 0
+}
+
+// etagListMatches reports whether current matches an entry of an If-Match
+// or If-None-Match list, using the strong or weak comparison. "*" matches
+// the current representation, which exists once a handler checks it.
+func etagListMatches(list, current string, strong bool) bool {
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	if current == "" {
+		return false
+	}
+
+	for list != "" {
+		var tag string
+		tag, list = scanETag(list)
+		if tag == "" {
+			return false
+		}
+
+		if strong {
+			if !isWeakETag(tag) && !isWeakETag(current) && tag == current {
+				return true
+			}
+		} else if strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(current, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// scanETag returns the first entity tag of s and the remainder after the
+// following comma, or "" when s does not start with a valid tag.
+func scanETag(s string) (tag, rest string) {
+	s = strings.TrimLeft(s, " \t,")
+
+	start := 0
+	if strings.HasPrefix(s, "W/") {
+		start = 2
+	}
+	if len(s)-start < 2 || s[start] != '"' {
+		return "", ""
+	}
+
+	end := strings.IndexByte(s[start+1:], '"')
+	if end < 0 {
+		return "", ""
+	}
+	end += start + 2
+
+	return s[:end], strings.TrimLeft(s[end:], " \t,")
+}
+
+func isWeakETag(tag string) bool { return strings.HasPrefix(tag, "W/") }
+
+// writeNotModified writes a 304, dropping the representation headers a
+// body-less response must not carry.
+func writeNotModified(w http.ResponseWriter) {
+	h := w.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	h.Del("Content-Encoding")
+	if h.Get("ETag") != "" {
+		h.Del("Last-Modified")
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
@@ -0,0 +1,157 @@
+package golly
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderConditional(t *testing.T) {
+	body := map[string]string{"key": "value"}
+
+	item := func(handler HandlerFunc) func(r *Route) {
+		return func(r *Route) { r.Get("/item", handler).Head("/item", handler) }
+	}
+
+	renderBody := func(wctx *WebContext) { wctx.RenderJSON(body) }
+
+	t.Run("it should not tag responses by default", func(t *testing.T) {
+		rec := serveTestApp(t, Options{}, item(renderBody), testRequest(http.MethodGet, "/item", nil, nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("ETag"))
+	})
+
+	t.Run("it should generate a stable strong ETag when enabled", func(t *testing.T) {
+		first := serveTestApp(t, Options{ETags: true}, item(renderBody), testRequest(http.MethodGet, "/item", nil, nil))
+		second := serveTestApp(t, Options{ETags: true}, item(renderBody), testRequest(http.MethodGet, "/item", nil, nil))
+
+		etag := first.Header().Get("ETag")
+		assert.Regexp(t, `^"[A-Za-z0-9_-]+"$`, etag)
+		assert.Equal(t, etag, second.Header().Get("ETag"))
+		assert.JSONEq(t, `{"key":"value"}`, first.Body.String())
+	})
+
+	t.Run("it should answer If-None-Match with 304", func(t *testing.T) {
+		etag := serveTestApp(t, Options{ETags: true}, item(renderBody), testRequest(http.MethodGet, "/item", nil, nil)).Header().Get("ETag")
+
+		for _, method := range []string{http.MethodGet, http.MethodHead} {
+			rec := serveTestApp(t, Options{ETags: true}, item(renderBody), testRequest(method, "/item", nil, map[string]string{"If-None-Match": `"other", W/` + etag}))
+
+			assert.Equal(t, http.StatusNotModified, rec.Code, method)
+			assert.Equal(t, etag, rec.Header().Get("ETag"), method)
+			assert.Empty(t, rec.Header().Get("Content-Type"), method)
+			assert.Zero(t, rec.Body.Len(), method)
+		}
+
+		rec := serveTestApp(t, Options{ETags: true}, item(renderBody), testRequest(http.MethodGet, "/item", nil, map[string]string{"If-None-Match": `"stale"`}))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("it should tag HEAD like GET", func(t *testing.T) {
+		get := serveTestApp(t, Options{ETags: true}, item(renderBody), testRequest(http.MethodGet, "/item", nil, nil))
+		head := serveTestApp(t, Options{ETags: true}, item(renderBody), testRequest(http.MethodHead, "/item", nil, nil))
+
+		assert.Equal(t, http.StatusOK, head.Code)
+		assert.Equal(t, get.Header().Get("ETag"), head.Header().Get("ETag"))
+		assert.Zero(t, head.Body.Len())
+	})
+
+	t.Run("it should keep a handler supplied weak ETag", func(t *testing.T) {
+		handler := func(wctx *WebContext) {
+			wctx.SetETag("v7", true)
+			wctx.RenderJSON(body)
+		}
+
+		rec := serveTestApp(t, Options{ETags: true}, item(handler), testRequest(http.MethodGet, "/item", nil, nil))
+		assert.Equal(t, `W/"v7"`, rec.Header().Get("ETag"))
+
+		rec = serveTestApp(t, Options{}, item(handler), testRequest(http.MethodGet, "/item", nil, map[string]string{"If-None-Match": `"v7"`}))
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+	})
+
+	t.Run("it should answer If-Modified-Since with 304", func(t *testing.T) {
+		modified := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+		handler := func(wctx *WebContext) {
+			wctx.SetLastModified(modified)
+			wctx.RenderJSON(body)
+		}
+
+		rec := serveTestApp(t, Options{}, item(handler), testRequest(http.MethodGet, "/item", nil, map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}))
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+
+		rec = serveTestApp(t, Options{}, item(handler), testRequest(http.MethodGet, "/item", nil, map[string]string{"If-Modified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, modified.Format(http.TimeFormat), rec.Header().Get("Last-Modified"))
+	})
+
+	t.Run("it should leave non-200 responses alone", func(t *testing.T) {
+		rec := serveTestApp(t, Options{ETags: true}, item(func(wctx *WebContext) {
+			wctx.WithStatus(http.StatusAccepted).RenderJSON(body)
+		}), testRequest(http.MethodGet, "/item", nil, map[string]string{"If-None-Match": "*"}))
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Empty(t, rec.Header().Get("ETag"))
+	})
+}
+
+func TestCheckPreconditions(t *testing.T) {
+	update := func(method string, headers map[string]string) (*httptest.ResponseRecorder, bool) {
+		rec := httptest.NewRecorder()
+		wctx := NewTestWebContext(testRequest(method, "/item", nil, headers), rec)
+
+		wctx.SetETag("v2", false)
+		wctx.SetLastModified(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+
+		stop := wctx.CheckPreconditions()
+		if !stop {
+			wctx.WithStatus(http.StatusNoContent)
+		}
+		return rec, stop
+	}
+
+	t.Run("it should let matching If-Match through", func(t *testing.T) {
+		for _, im := range []string{`"v2"`, `"v1", "v2"`, "*"} {
+			rec, stop := update(http.MethodPut, map[string]string{"If-Match": im})
+
+			assert.False(t, stop, im)
+			assert.Equal(t, http.StatusNoContent, rec.Code, im)
+		}
+	})
+
+	t.Run("it should fail stale or weak If-Match with 412", func(t *testing.T) {
+		for _, im := range []string{`"v1"`, `W/"v2"`, "garbage"} {
+			rec, stop := update(http.MethodPut, map[string]string{"If-Match": im})
+
+			assert.True(t, stop, im)
+			assert.Equal(t, http.StatusPreconditionFailed, rec.Code, im)
+			assert.Contains(t, rec.Body.String(), ErrPreconditionFailed.Error(), im)
+		}
+	})
+
+	t.Run("it should fail If-Unmodified-Since after a change", func(t *testing.T) {
+		rec, stop := update(http.MethodPatch, map[string]string{"If-Unmodified-Since": "Wed, 01 May 2024 11:00:00 GMT"})
+		assert.True(t, stop)
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+		_, stop = update(http.MethodPatch, map[string]string{"If-Unmodified-Since": "Wed, 01 May 2024 12:00:00 GMT"})
+		assert.False(t, stop)
+	})
+
+	t.Run("it should fail If-None-Match: * on unsafe methods", func(t *testing.T) {
+		rec, stop := update(http.MethodPut, map[string]string{"If-None-Match": "*"})
+
+		assert.True(t, stop)
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+
+	t.Run("it should proceed without conditional headers", func(t *testing.T) {
+		rec, stop := update(http.MethodDelete, nil)
+
+		assert.False(t, stop)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+}
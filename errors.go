@@ -1,6 +1,7 @@
 package golly
 
 import (
+	"errors"
 	"maps"
 	"net/http"
 
@@ -103,6 +104,27 @@ func NewError(code uint, cause error, ext ...map[string]any) *Error {
 	}
 }
 
+// AsError converts err into an *Error: an *Error in the chain is returned
+// as is, an HTTPError keeps its status and message, anything else becomes
+// an opaque 500 so internal details are not exposed to clients.
+func AsError(err error) *Error {
+	var gerr *Error
+	if errors.As(err, &gerr) {
+		return gerr
+	}
+
+	var herr HTTPError
+	if errors.As(err, &herr) {
+		e := NewError(uint(herr.Status()), err)
+		e.message = herr.Message()
+		return e
+	}
+
+	e := NewError(http.StatusInternalServerError, err)
+	e.message = http.StatusText(http.StatusInternalServerError)
+	return e
+}
+
 // --- helpers ---
 
 func copyExt(in map[string]any) map[string]any {
@@ -0,0 +1,38 @@
+package golly
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// serveRequest routes req through a and returns the recorded response.
+func serveRequest(a *Application, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	RouteRequest(a, req, rec)
+	return rec
+}
+
+// serveTestApp builds a test application with opts, lets setup register
+// its routes and routes req through it.
+func serveTestApp(t *testing.T, opts Options, setup func(r *Route), req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+
+	a, err := NewTestApplication(opts)
+	require.NoError(t, err)
+	setup(a.routes)
+
+	return serveRequest(a, req)
+}
+
+// testRequest is httptest.NewRequest with headers set.
+func testRequest(method, target string, body io.Reader, headers map[string]string) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
@@ -17,6 +17,7 @@ package h2c
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"sync/atomic"
 	"time"
@@ -134,7 +135,9 @@ func (s *Service) Stop() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	return s.server.Shutdown(ctx)
+	return errors.Join(
+		s.server.Shutdown(ctx),
+		s.app.CloseWebSockets(ctx))
 }
 
 // ServeHTTP delegates to golly's router.
@@ -175,7 +175,9 @@ func (s *Service) Stop() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	return s.server.Shutdown(ctx)
+	return errors.Join(
+		s.server.Shutdown(ctx),
+		s.app.CloseWebSockets(ctx))
 }
 
 // ServeHTTP delegates to golly's router.
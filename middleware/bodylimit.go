@@ -0,0 +1,22 @@
+package middleware
+
+import "github.com/golly-go/golly"
+
+// BodyLimit caps request bodies at n bytes for the routes it wraps,
+// replacing golly.Options.MaxBodySize, so upload routes can accept more
+// and small JSON endpoints less. Reads past the cap fail with a 413
+// *golly.Error (see WebContext.LimitBody).
+//
+// Example:
+//
+//	app.Routes().Namespace("/uploads", func(r *golly.Route) {
+//	    r.Use(middleware.BodyLimit(512 << 20))
+//	})
+func BodyLimit(n int64) func(next golly.HandlerFunc) golly.HandlerFunc {
+	return func(next golly.HandlerFunc) golly.HandlerFunc {
+		return func(wctx *golly.WebContext) {
+			wctx.LimitBody(n)
+			next(wctx)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golly-go/golly"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimit(t *testing.T) {
+	read := func(limit int64, body string) error {
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		wctx := golly.NewTestWebContext(request, httptest.NewRecorder())
+
+		var err error
+		BodyLimit(limit)(func(wctx *golly.WebContext) {
+			_, err = wctx.Body()
+		})(wctx)
+		return err
+	}
+
+	t.Run("it should let bodies within the limit through", func(t *testing.T) {
+		assert.NoError(t, read(5, "12345"))
+	})
+
+	t.Run("it should fail oversized bodies with 413", func(t *testing.T) {
+		err := read(4, "12345")
+
+		var gerr *golly.Error
+		assert.True(t, errors.As(err, &gerr))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, gerr.Status())
+		assert.ErrorIs(t, err, golly.ErrBodyTooLarge)
+	})
+}
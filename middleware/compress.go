@@ -0,0 +1,417 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golly-go/golly"
+)
+
+const (
+	acceptEncodingHeader  = "Accept-Encoding"
+	contentEncodingHeader = "Content-Encoding"
+
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+
+	defaultCompressMinSize = 1024
+)
+
+// defaultCompressTypes are compressed when CompressOptions.Types is empty.
+// Images, archives and other already-compressed formats are left alone.
+var defaultCompressTypes = []string{
+	"text/*",
+	"application/json",
+	"application/*+json",
+	"application/x-ndjson",
+	"application/javascript",
+	"application/xml",
+	"application/*+xml",
+	"application/wasm",
+	"image/svg+xml",
+}
+
+// CompressOptions defines the compression options
+type CompressOptions struct {
+	// Level is the gzip/deflate level, from gzip.HuffmanOnly to
+	// gzip.BestCompression; zero means the default level
+	Level int
+
+	// MinSize is the smallest body worth compressing (default 1024 bytes);
+	// responses flushed before reaching it are sent uncompressed
+	MinSize int
+
+	// Types lists the compressible media types; "text/*" matches a whole
+	// type and "application/*+json" a structured syntax suffix
+	Types []string
+}
+
+type compressor struct {
+	minSize int
+	types   []string
+
+	gzipPool sync.Pool
+	zlibPool sync.Pool
+}
+
+func (c CompressOptions) init() *compressor {
+	co := &compressor{minSize: c.MinSize, types: c.Types}
+
+	if co.minSize <= 0 {
+		co.minSize = defaultCompressMinSize
+	}
+	if len(co.types) == 0 {
+		co.types = defaultCompressTypes
+	}
+
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		panic("middleware: Compress Level must be between gzip.HuffmanOnly and gzip.BestCompression")
+	}
+
+	co.gzipPool.New = func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, level)
+		return w
+	}
+	// "deflate" is zlib-wrapped DEFLATE (RFC 9110 section 8.4.1.2)
+	co.zlibPool.New = func() any {
+		w, _ := zlib.NewWriterLevel(io.Discard, level)
+		return w
+	}
+
+	return co
+}
+
+// Compress builds a golly middleware compressing response bodies with gzip
+// or deflate, picked from Accept-Encoding. Bodies are buffered up to
+// MinSize to decide; small bodies, non-compressible content types and
+// responses that already carry a Content-Encoding pass through untouched.
+//
+// The compressing writer wraps the request's WrapResponseWriter: Status
+// reports the handler's status, BytesWritten the (compressed) bytes sent to
+// the client and Tee receives the uncompressed body. Flush flushes the
+// encoder, so streaming keeps working, and Hijack bypasses compression.
+func Compress(opts CompressOptions) func(next golly.HandlerFunc) golly.HandlerFunc {
+	co := opts.init()
+
+	return func(next golly.HandlerFunc) golly.HandlerFunc {
+		return func(wctx *golly.WebContext) {
+			r := wctx.Request()
+
+			inner, ok := wctx.Response().(golly.WrapResponseWriter)
+			if !ok || r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" {
+				next(wctx)
+				return
+			}
+
+			inner.Header().Add(Vary, acceptEncodingHeader)
+
+			encoding := negotiateEncoding(r.Header.Values(acceptEncodingHeader))
+			if encoding == "" {
+				next(wctx)
+				return
+			}
+
+			cw := &compressWriter{WrapResponseWriter: inner, co: co, encoding: encoding}
+			wctx.WithResponse(cw)
+
+			defer func() {
+				cw.close()
+				wctx.WithResponse(inner)
+			}()
+
+			next(wctx)
+		}
+	}
+}
+
+// negotiateEncoding picks gzip or deflate from Accept-Encoding values,
+// preferring gzip on equal quality; "" means send the body as is.
+func negotiateEncoding(values []string) string {
+	var gzipQ, deflateQ, starQ float64 = -1, -1, -1
+
+	for _, v := range values {
+		for part := range strings.SplitSeq(v, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+			q := 1.0
+			if qs, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if f, err := strconv.ParseFloat(qs, 64); err == nil {
+					q = f
+				}
+			}
+
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case encodingGzip, "x-gzip":
+				gzipQ = q
+			case encodingDeflate:
+				deflateQ = q
+			case star:
+				starQ = q
+			}
+		}
+	}
+
+	if gzipQ < 0 {
+		gzipQ = starQ
+	}
+	if deflateQ < 0 {
+		deflateQ = starQ
+	}
+
+	switch {
+	case gzipQ > 0 && gzipQ >= deflateQ:
+		return encodingGzip
+	case deflateQ > 0:
+		return encodingDeflate
+	}
+	return ""
+}
+
+// compressible reports whether the media type is in the configured list.
+func (c *compressor) compressible(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	typ, subtype, _ := strings.Cut(mt, "/")
+
+	for _, pattern := range c.types {
+		pt, ps, _ := strings.Cut(pattern, "/")
+		if pt != typ && pt != star {
+			continue
+		}
+
+		switch {
+		case ps == subtype, ps == star:
+			return true
+		case strings.HasPrefix(ps, "*+") && strings.HasSuffix(subtype, ps[1:]):
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter buffers the start of the body to decide whether to
+// compress, then streams through a pooled encoder.
+type compressWriter struct {
+	golly.WrapResponseWriter
+
+	co       *compressor
+	encoding string
+
+	code     int
+	buf      []byte
+	decided  bool
+	hijacked bool
+	tee      io.Writer
+
+	enc interface {
+		io.WriteCloser
+		Flush() error
+	}
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.code != 0 || cw.decided {
+		return
+	}
+
+	// Informational responses pass straight through
+	if code >= 100 && code <= 199 {
+		cw.WrapResponseWriter.WriteHeader(code)
+		return
+	}
+
+	cw.code = code
+
+	// No body is coming, so there is nothing to compress
+	if code == http.StatusNoContent || code == http.StatusNotModified {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if cw.code == 0 {
+		cw.code = http.StatusOK
+	}
+
+	if cw.tee != nil {
+		cw.tee.Write(b) //nolint:errcheck
+	}
+
+	if !cw.decided {
+		cw.buf = append(cw.buf, b...)
+		if len(cw.buf) < cw.co.minSize {
+			return len(b), nil
+		}
+
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	if cw.enc != nil {
+		return cw.enc.Write(b)
+	}
+	return cw.WrapResponseWriter.Write(b)
+}
+
+// decide commits the headers and either starts the encoder or falls back
+// to writing the body as is, then drains the buffer. large says whether
+// the body reached MinSize.
+func (cw *compressWriter) decide(large bool) error {
+	cw.decided = true
+
+	h := cw.Header()
+	if h.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+
+	if large && cw.shouldCompress(h) {
+		h.Del("Content-Length")
+		h.Set(contentEncodingHeader, cw.encoding)
+
+		// The encoded bytes differ from what a strong ETag promises
+		if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			h.Set("ETag", "W/"+etag)
+		}
+		cw.enc = cw.co.encoder(cw.encoding, cw.WrapResponseWriter)
+	}
+
+	if cw.code == 0 {
+		cw.code = http.StatusOK
+	}
+	cw.WrapResponseWriter.WriteHeader(cw.code)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(buf)
+	} else {
+		_, err = cw.WrapResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (cw *compressWriter) shouldCompress(h http.Header) bool {
+	if h.Get(contentEncodingHeader) != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+	if cw.code == http.StatusPartialContent {
+		return false
+	}
+	return cw.co.compressible(h.Get("Content-Type"))
+}
+
+// Flush commits whatever is buffered (uncompressed when still below
+// MinSize), flushes the encoder and then the underlying writer.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		cw.decide(false) //nolint:errcheck
+	}
+
+	if cw.enc != nil {
+		cw.enc.Flush() //nolint:errcheck
+	}
+
+	if f, ok := cw.WrapResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hands the connection over untouched; nothing buffered is sent.
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := cw.WrapResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		cw.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Push implements http.Pusher
+func (cw *compressWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := cw.WrapResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+func (cw *compressWriter) Status() int {
+	if cw.code != 0 {
+		return cw.code
+	}
+	return cw.WrapResponseWriter.Status()
+}
+
+func (cw *compressWriter) Tee(w io.Writer) { cw.tee = w }
+
+// close finishes the response: small bodies are written as is, the
+// encoder is closed and returned to its pool.
+func (cw *compressWriter) close() {
+	if cw.hijacked {
+		return
+	}
+
+	if !cw.decided && (cw.code != 0 || len(cw.buf) > 0) {
+		cw.decide(len(cw.buf) >= cw.co.minSize) //nolint:errcheck
+	}
+
+	if cw.enc != nil {
+		cw.enc.Close() //nolint:errcheck
+		cw.co.release(cw.encoding, cw.enc)
+		cw.enc = nil
+	}
+}
+
+func (c *compressor) encoder(encoding string, w io.Writer) interface {
+	io.WriteCloser
+	Flush() error
+} {
+	if encoding == encodingGzip {
+		gz := c.gzipPool.Get().(*gzip.Writer)
+		gz.Reset(w)
+		return gz
+	}
+
+	zw := c.zlibPool.Get().(*zlib.Writer)
+	zw.Reset(w)
+	return zw
+}
+
+func (c *compressor) release(encoding string, enc io.WriteCloser) {
+	if encoding == encodingGzip {
+		c.gzipPool.Put(enc)
+		return
+	}
+	c.zlibPool.Put(enc)
+}
+
+// Compile time checks
+var (
+	_ golly.WrapResponseWriter = &compressWriter{}
+	_ http.Flusher             = &compressWriter{}
+	_ http.Hijacker            = &compressWriter{}
+)
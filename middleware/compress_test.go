@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golly-go/golly"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runCompress(opts CompressOptions, acceptEncoding string, handler golly.HandlerFunc) (*httptest.ResponseRecorder, *golly.WebContext) {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptEncoding != "" {
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
+	wctx := golly.NewTestWebContext(request, recorder)
+	Compress(opts)(handler)(wctx)
+
+	return recorder, wctx
+}
+
+func writeBody(contentType, body string) golly.HandlerFunc {
+	return func(wctx *golly.WebContext) {
+		if contentType != "" {
+			wctx.Response().Header().Set("Content-Type", contentType)
+		}
+		wctx.Response().Write([]byte(body))
+	}
+}
+
+func gunzip(t *testing.T, b []byte) string {
+	t.Helper()
+
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	require.NoError(t, err)
+
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(`{"hello":"world"}`, 200)
+
+	t.Run("it should gzip large compressible bodies", func(t *testing.T) {
+		rec, _ := runCompress(CompressOptions{}, "gzip, deflate", writeBody("application/json", large))
+
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Contains(t, rec.Header().Values("Vary"), "Accept-Encoding")
+		assert.Less(t, rec.Body.Len(), len(large))
+		assert.Equal(t, large, gunzip(t, rec.Body.Bytes()))
+	})
+
+	t.Run("it should use deflate when preferred", func(t *testing.T) {
+		rec, _ := runCompress(CompressOptions{}, "gzip;q=0.5, deflate", writeBody("text/plain", large))
+
+		assert.Equal(t, "deflate", rec.Header().Get("Content-Encoding"))
+
+		zr, err := zlib.NewReader(rec.Body)
+		require.NoError(t, err)
+
+		out, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, large, string(out))
+	})
+
+	t.Run("it should honour q=0 and identity-only clients", func(t *testing.T) {
+		for _, ae := range []string{"", "identity", "gzip;q=0", "*;q=0"} {
+			rec, _ := runCompress(CompressOptions{}, ae, writeBody("application/json", large))
+
+			assert.Empty(t, rec.Header().Get("Content-Encoding"), ae)
+			assert.Equal(t, large, rec.Body.String(), ae)
+		}
+	})
+
+	t.Run("it should skip bodies below MinSize", func(t *testing.T) {
+		rec, _ := runCompress(CompressOptions{}, "gzip", writeBody("application/json", `{"ok":true}`))
+
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, `{"ok":true}`, rec.Body.String())
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("it should skip non-compressible types", func(t *testing.T) {
+		rec, _ := runCompress(CompressOptions{}, "gzip", writeBody("image/png", large))
+
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, large, rec.Body.String())
+	})
+
+	t.Run("it should sniff a missing content type", func(t *testing.T) {
+		body := "<html><body>" + strings.Repeat("hello ", 400) + "</body></html>"
+		rec, _ := runCompress(CompressOptions{}, "gzip", writeBody("", body))
+
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+		assert.Equal(t, body, gunzip(t, rec.Body.Bytes()))
+	})
+
+	t.Run("it should match custom types and suffixes", func(t *testing.T) {
+		opts := CompressOptions{MinSize: 10, Types: []string{"application/*+json"}}
+
+		rec, _ := runCompress(opts, "gzip", writeBody("application/problem+json", large))
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+
+		rec, _ = runCompress(opts, "gzip", writeBody("text/plain", large))
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("it should leave encoded and partial responses alone", func(t *testing.T) {
+		rec, _ := runCompress(CompressOptions{}, "gzip", func(wctx *golly.WebContext) {
+			wctx.Response().Header().Set("Content-Encoding", "br")
+			writeBody("text/plain", large)(wctx)
+		})
+		assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, large, rec.Body.String())
+
+		rec, _ = runCompress(CompressOptions{}, "gzip", func(wctx *golly.WebContext) {
+			wctx.Response().Header().Set("Content-Type", "text/plain")
+			wctx.Response().WriteHeader(http.StatusPartialContent)
+			wctx.Response().Write([]byte(large))
+		})
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("it should keep status-only responses intact", func(t *testing.T) {
+		rec, _ := runCompress(CompressOptions{}, "gzip", func(wctx *golly.WebContext) {
+			wctx.Response().WriteHeader(http.StatusNoContent)
+		})
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Zero(t, rec.Body.Len())
+	})
+
+	t.Run("it should report status, wire bytes and tee the plain body", func(t *testing.T) {
+		var tee bytes.Buffer
+		var status, written int
+
+		rec, wctx := runCompress(CompressOptions{}, "gzip", func(wctx *golly.WebContext) {
+			w := wctx.Response().(golly.WrapResponseWriter)
+			w.Tee(&tee)
+			w.WriteHeader(http.StatusCreated)
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(large))
+			w.(http.Flusher).Flush()
+
+			status, written = w.Status(), w.BytesWritten()
+		})
+
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, large, tee.String())
+		assert.Less(t, written, len(large))
+
+		// The original writer is restored once the middleware returns
+		w := wctx.Response().(golly.WrapResponseWriter)
+		assert.Equal(t, rec.Body.Len(), w.BytesWritten())
+	})
+
+	t.Run("it should flush compressed chunks while streaming", func(t *testing.T) {
+		var flushed int
+
+		rec, _ := runCompress(CompressOptions{MinSize: 16}, "gzip", func(wctx *golly.WebContext) {
+			w := wctx.Response()
+			w.Header().Set("Content-Type", "application/x-ndjson")
+
+			w.Write([]byte(strings.Repeat(`{"n":1}`+"\n", 10)))
+			w.(http.Flusher).Flush()
+
+			flushed = len(wctx.Response().(golly.WrapResponseWriter).Unwrap().(*httptest.ResponseRecorder).Body.Bytes())
+			w.Write([]byte(strings.Repeat(`{"n":2}`+"\n", 10)))
+		})
+
+		assert.Positive(t, flushed)
+		assert.True(t, rec.Flushed)
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, strings.Repeat(`{"n":1}`+"\n", 10)+strings.Repeat(`{"n":2}`+"\n", 10), gunzip(t, rec.Body.Bytes()))
+	})
+
+	t.Run("it should not compress HEAD or upgrade requests", func(t *testing.T) {
+		for _, mutate := range []func(*http.Request){
+			func(r *http.Request) { r.Method = http.MethodHead },
+			func(r *http.Request) { r.Header.Set("Upgrade", "websocket") },
+		} {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept-Encoding", "gzip")
+			mutate(request)
+
+			wctx := golly.NewTestWebContext(request, recorder)
+			Compress(CompressOptions{})(writeBody("text/plain", large))(wctx)
+
+			assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+		}
+	})
+
+	t.Run("it should panic on an out of range level", func(t *testing.T) {
+		assert.Panics(t, func() { Compress(CompressOptions{Level: 12}) })
+		assert.Panics(t, func() { Compress(CompressOptions{Level: -3}) })
+		assert.NotPanics(t, func() { Compress(CompressOptions{Level: gzip.HuffmanOnly}) })
+		assert.NotPanics(t, func() { Compress(CompressOptions{Level: gzip.BestCompression}) })
+	})
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0.2, deflate;q=0.8", "deflate"},
+		{"*", "gzip"},
+		{"*;q=0.5, gzip;q=0", "deflate"},
+		{"br", ""},
+		{"identity", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, negotiateEncoding([]string{tt.header}))
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golly-go/golly"
+)
+
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+const (
+	rateLimitLimitHeader     = "RateLimit-Limit"
+	rateLimitRemainingHeader = "RateLimit-Remaining"
+	rateLimitResetHeader     = "RateLimit-Reset"
+	rateLimitPolicyHeader    = "RateLimit-Policy"
+	retryAfterHeader         = "Retry-After"
+)
+
+// RateLimitAlgorithm selects how requests are counted.
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket refills Requests tokens per Window into a bucket of
+	// Burst tokens, allowing short bursts
+	TokenBucket RateLimitAlgorithm = iota
+
+	// SlidingWindow allows Requests per rolling Window, weighting the
+	// previous window by how much of it still overlaps
+	SlidingWindow
+)
+
+// RateLimitPolicy is the limit a store enforces for a key.
+type RateLimitPolicy struct {
+	Algorithm RateLimitAlgorithm
+	Requests  int
+	Window    time.Duration
+
+	// Burst is the token bucket capacity (default Requests)
+	Burst int
+}
+
+// capacity is the most requests the policy lets through at once.
+func (p RateLimitPolicy) capacity() int {
+	if p.Algorithm == TokenBucket && p.Burst > 0 {
+		return p.Burst
+	}
+	return p.Requests
+}
+
+// RateLimitResult is the outcome of a RateLimitStore.Take.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+
+	// Reset is how long until the full limit is available again
+	Reset time.Duration
+
+	// RetryAfter is how long until the next request is allowed (0 when
+	// Allowed)
+	RetryAfter time.Duration
+}
+
+// RateLimitStore counts requests per key. Implementations backed by a
+// shared store (Redis and the like) let several instances enforce one
+// limit; they must be safe for concurrent use.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error)
+}
+
+// RateLimitKeyFunc derives the key requests are counted under. An empty
+// key skips limiting for the request.
+type RateLimitKeyFunc func(wctx *golly.WebContext) string
+
+// RateLimitOptions configures RateLimit.
+type RateLimitOptions struct {
+	Algorithm RateLimitAlgorithm
+
+	// Requests allowed per Window
+	Requests int
+	Window   time.Duration
+
+	// Burst is the token bucket capacity (default Requests)
+	Burst int
+
+	// Key picks the client (default KeyByIP)
+	Key RateLimitKeyFunc
+
+	// PerRoute gives every route its own budget instead of sharing one
+	// across the routes the middleware wraps
+	PerRoute bool
+
+	// Store keeps the counters (default a new MemoryRateLimitStore)
+	Store RateLimitStore
+}
+
+// RateLimit limits requests per key, answering with RateLimit-Limit,
+// RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers and,
+// once the limit is hit, a 429 *golly.Error (ErrRateLimited) with
+// Retry-After. Store errors are logged and let the request through.
+//
+// Example:
+//
+//	app.Routes().Namespace("/api", func(r *golly.Route) {
+//	    r.Use(middleware.RateLimit(middleware.RateLimitOptions{
+//	        Requests: 100,
+//	        Window:   time.Minute,
+//	        Key:      middleware.KeyByIdentity(func(u *User) string { return u.ID }),
+//	        PerRoute: true,
+//	    }))
+//	})
+func RateLimit(opts RateLimitOptions) func(next golly.HandlerFunc) golly.HandlerFunc {
+	if opts.Requests <= 0 || opts.Window <= 0 {
+		panic("middleware: RateLimit needs positive Requests and Window")
+	}
+	if opts.Key == nil {
+		opts.Key = KeyByIP
+	}
+	if opts.Store == nil {
+		opts.Store = NewMemoryRateLimitStore()
+	}
+
+	policy := RateLimitPolicy{
+		Algorithm: opts.Algorithm,
+		Requests:  opts.Requests,
+		Window:    opts.Window,
+		Burst:     opts.Burst,
+	}
+	policyHeader := strconv.Itoa(policy.capacity()) + ";w=" + strconv.Itoa(int(math.Ceil(opts.Window.Seconds())))
+
+	var patterns sync.Map // *golly.Route -> pattern
+
+	return func(next golly.HandlerFunc) golly.HandlerFunc {
+		return func(wctx *golly.WebContext) {
+			key := opts.Key(wctx)
+			if key == "" {
+				next(wctx)
+				return
+			}
+
+			if route := wctx.Route(); opts.PerRoute && route != nil {
+				pattern, ok := patterns.Load(route)
+				if !ok {
+					pattern, _ = patterns.LoadOrStore(route, route.Pattern())
+				}
+				key = wctx.Request().Method + " " + pattern.(string) + "|" + key
+			}
+
+			res, err := opts.Store.Take(wctx.Context(), key, policy)
+			if err != nil {
+				wctx.Logger().WithError(err).Error("rate limit store failed")
+				next(wctx)
+				return
+			}
+
+			h := wctx.ResponseHeaders()
+			h.Set(rateLimitLimitHeader, strconv.Itoa(res.Limit))
+			h.Set(rateLimitRemainingHeader, strconv.Itoa(res.Remaining))
+			h.Set(rateLimitResetHeader, ceilSeconds(res.Reset))
+			h.Set(rateLimitPolicyHeader, policyHeader)
+
+			if !res.Allowed {
+				retry := ceilSeconds(res.RetryAfter)
+				h.Set(retryAfterHeader, retry)
+
+				wctx.RenderError(golly.NewError(http.StatusTooManyRequests, ErrRateLimited, map[string]any{"retry_after": retry}))
+				return
+			}
+
+			next(wctx)
+		}
+	}
+}
+
+// ceilSeconds formats d as whole seconds, rounding up.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// KeyByIP keys requests by the connection's remote IP. Behind a proxy use
+// KeyByHeader with the header the proxy sets (e.g. X-Real-IP).
+func KeyByIP(wctx *golly.WebContext) string {
+	addr := wctx.Request().RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// KeyByHeader keys requests by a header such as an API key; requests
+// without it are not limited.
+func KeyByHeader(name string) RateLimitKeyFunc {
+	return func(wctx *golly.WebContext) string {
+		return wctx.Request().Header.Get(name)
+	}
+}
+
+// KeyByIdentity keys requests by the golly.Identity on the request
+// context, falling back to the remote IP for anonymous requests.
+func KeyByIdentity[T golly.Identity](id func(T) string) RateLimitKeyFunc {
+	return func(wctx *golly.WebContext) string {
+		ident := golly.IdentityFromContext[T](wctx.Context())
+		if !reflect.ValueOf(&ident).Elem().IsZero() && ident.IsValid() {
+			if key := id(ident); key != "" {
+				return "identity:" + key
+			}
+		}
+		return "ip:" + KeyByIP(wctx)
+	}
+}
+
+const rateLimitShards = 32
+
+// MemoryRateLimitStore is an in-process RateLimitStore. Counters are kept
+// per instance, so each server enforces its own limit. Idle keys are swept
+// as requests come in.
+type MemoryRateLimitStore struct {
+	shards [rateLimitShards]rateLimitShard
+	now    func() time.Time
+}
+
+type rateLimitShard struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	lastSweep time.Time
+}
+
+type rateLimitEntry struct {
+	// Token bucket
+	tokens float64
+	last   time.Time
+
+	// Sliding window
+	windowStart time.Time
+	current     int
+	previous    int
+
+	expires time.Time
+}
+
+// NewMemoryRateLimitStore returns an empty in-memory store.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	s := &MemoryRateLimitStore{now: time.Now}
+	for i := range s.shards {
+		s.shards[i].entries = map[string]*rateLimitEntry{}
+	}
+	return s
+}
+
+// Take implements RateLimitStore.
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	now := s.now()
+	shard := &s.shards[fnv32(key)%rateLimitShards]
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	shard.sweep(now, policy.Window)
+
+	e, ok := shard.entries[key]
+	if !ok {
+		e = &rateLimitEntry{tokens: float64(policy.capacity()), last: now, windowStart: now.Truncate(policy.Window)}
+		shard.entries[key] = e
+	}
+
+	if policy.Algorithm == SlidingWindow {
+		return e.slidingWindow(now, policy), nil
+	}
+	return e.tokenBucket(now, policy), nil
+}
+
+// sweep drops expired entries at most once per window.
+func (sh *rateLimitShard) sweep(now time.Time, window time.Duration) {
+	if now.Sub(sh.lastSweep) < window {
+		return
+	}
+	sh.lastSweep = now
+
+	for key, e := range sh.entries {
+		if now.After(e.expires) {
+			delete(sh.entries, key)
+		}
+	}
+}
+
+func (e *rateLimitEntry) tokenBucket(now time.Time, p RateLimitPolicy) RateLimitResult {
+	capacity := float64(p.capacity())
+	rate := float64(p.Requests) / p.Window.Seconds() // tokens per second
+
+	e.tokens = min(capacity, e.tokens+now.Sub(e.last).Seconds()*rate)
+	e.last = now
+
+	res := RateLimitResult{Limit: int(capacity)}
+	if e.tokens >= 1 {
+		e.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - e.tokens) / rate)
+	}
+
+	res.Remaining = int(e.tokens)
+	res.Reset = seconds((capacity - e.tokens) / rate)
+	e.expires = now.Add(res.Reset)
+	return res
+}
+
+func (e *rateLimitEntry) slidingWindow(now time.Time, p RateLimitPolicy) RateLimitResult {
+	start := now.Truncate(p.Window)
+
+	switch elapsed := start.Sub(e.windowStart); {
+	case elapsed == p.Window:
+		e.previous, e.current = e.current, 0
+	case elapsed > p.Window:
+		e.previous, e.current = 0, 0
+	}
+	e.windowStart = start
+
+	into := now.Sub(start)
+	weight := 1 - float64(into)/float64(p.Window)
+	estimate := float64(e.previous)*weight + float64(e.current)
+
+	res := RateLimitResult{Limit: p.Requests, Reset: start.Add(p.Window).Sub(now)}
+
+	if estimate+1 <= float64(p.Requests) {
+		e.current++
+		estimate++
+		res.Allowed = true
+	} else if e.current+1 > p.Requests {
+		// This window is full; in the next one it becomes the previous
+		// window and its weight has to drop far enough
+		needed := 1 - float64(p.Requests-1)/float64(e.current)
+		res.RetryAfter = max(res.Reset+time.Duration(needed*float64(p.Window)), 0)
+	} else {
+		// The previous window's weight has to drop far enough
+		needed := 1 - float64(p.Requests-1-e.current)/float64(e.previous)
+		res.RetryAfter = max(time.Duration(needed*float64(p.Window))-into, time.Millisecond)
+	}
+
+	res.Remaining = max(p.Requests-int(math.Ceil(estimate)), 0)
+	e.expires = start.Add(2 * p.Window)
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// fnv32 hashes a key to a shard.
+func fnv32(key string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return h
+}
+
+var _ RateLimitStore = (*MemoryRateLimitStore)(nil)
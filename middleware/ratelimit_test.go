@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golly-go/golly"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rateLimitUser struct{ id string }
+
+func (u *rateLimitUser) Valid() error  { return nil }
+func (u *rateLimitUser) IsValid() bool { return u != nil }
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, RateLimitPolicy) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("store down")
+}
+
+func TestRateLimit(t *testing.T) {
+	ok := func(wctx *golly.WebContext) { wctx.RenderText("ok") }
+
+	hit := func(h golly.HandlerFunc, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		h(golly.NewTestWebContext(req, rec))
+		return rec
+	}
+
+	t.Run("it should answer 429 with headers once the limit is hit", func(t *testing.T) {
+		h := RateLimit(RateLimitOptions{Requests: 2, Window: time.Minute})(ok)
+
+		rec := hit(h, "10.0.0.1:1234")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "2;w=60", rec.Header().Get("RateLimit-Policy"))
+
+		hit(h, "10.0.0.1:1234")
+		rec = hit(h, "10.0.0.1:5678")
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+		assert.Contains(t, rec.Body.String(), ErrRateLimited.Error())
+
+		// Other clients have their own budget
+		assert.Equal(t, http.StatusOK, hit(h, "10.0.0.2:1234").Code)
+	})
+
+	t.Run("it should key by header and skip requests without it", func(t *testing.T) {
+		h := RateLimit(RateLimitOptions{Requests: 1, Window: time.Minute, Key: KeyByHeader("X-API-Key")})(ok)
+
+		send := func(key string) int {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if key != "" {
+				req.Header.Set("X-API-Key", key)
+			}
+			rec := httptest.NewRecorder()
+			h(golly.NewTestWebContext(req, rec))
+			return rec.Code
+		}
+
+		assert.Equal(t, http.StatusOK, send("a"))
+		assert.Equal(t, http.StatusTooManyRequests, send("a"))
+		assert.Equal(t, http.StatusOK, send("b"))
+		assert.Equal(t, http.StatusOK, send(""))
+		assert.Equal(t, http.StatusOK, send(""))
+	})
+
+	t.Run("it should key by identity and fall back to the IP", func(t *testing.T) {
+		key := KeyByIdentity(func(u *rateLimitUser) string { return u.id })
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx := golly.IdentityToContext(nil, &rateLimitUser{id: "u1"})
+		assert.Equal(t, "identity:u1", key(golly.NewWebContext(ctx, req, httptest.NewRecorder())))
+
+		assert.Equal(t, "ip:192.0.2.1", key(golly.NewTestWebContext(req, httptest.NewRecorder())))
+	})
+
+	t.Run("it should give each route its own budget with PerRoute", func(t *testing.T) {
+		a, err := golly.NewTestApplication(golly.Options{})
+		require.NoError(t, err)
+
+		a.Routes().Namespace("/api", func(r *golly.Route) {
+			r.Use(RateLimit(RateLimitOptions{Requests: 1, Window: time.Minute, PerRoute: true}))
+			r.Get("/users/{id}", ok)
+			r.Get("/orders", ok)
+		})
+
+		send := func(path string) int {
+			rec := httptest.NewRecorder()
+			golly.RouteRequest(a, httptest.NewRequest(http.MethodGet, path, nil), rec)
+			return rec.Code
+		}
+
+		assert.Equal(t, http.StatusOK, send("/api/users/1"))
+		assert.Equal(t, http.StatusTooManyRequests, send("/api/users/2"))
+		assert.Equal(t, http.StatusOK, send("/api/orders"))
+	})
+
+	t.Run("it should let requests through when the store fails", func(t *testing.T) {
+		h := RateLimit(RateLimitOptions{Requests: 1, Window: time.Minute, Store: failingStore{}})(ok)
+		assert.Equal(t, http.StatusOK, hit(h, "10.0.0.1:1").Code)
+	})
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newStore := func() *MemoryRateLimitStore {
+		s := NewMemoryRateLimitStore()
+		s.now = func() time.Time { return clock }
+		return s
+	}
+
+	take := func(s *MemoryRateLimitStore, p RateLimitPolicy) RateLimitResult {
+		res, err := s.Take(context.Background(), "k", p)
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("it should allow bursts and refill the token bucket", func(t *testing.T) {
+		s := newStore()
+		p := RateLimitPolicy{Requests: 10, Window: 10 * time.Second, Burst: 3}
+
+		for range 3 {
+			assert.True(t, take(s, p).Allowed)
+		}
+
+		res := take(s, p)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 3, res.Limit)
+		assert.Equal(t, time.Second, res.RetryAfter)
+
+		clock = clock.Add(time.Second)
+		res = take(s, p)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+	})
+
+	t.Run("it should weight the previous sliding window", func(t *testing.T) {
+		clock = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		s := newStore()
+		p := RateLimitPolicy{Algorithm: SlidingWindow, Requests: 4, Window: time.Minute}
+
+		for range 4 {
+			assert.True(t, take(s, p).Allowed)
+		}
+
+		res := take(s, p)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 75*time.Second, res.RetryAfter)
+
+		// A quarter into the next window 3 of the 4 earlier requests still count
+		clock = clock.Add(res.RetryAfter)
+		res = take(s, p)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+
+		res = take(s, p)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 15*time.Second, res.RetryAfter)
+
+		clock = clock.Add(res.RetryAfter)
+		assert.True(t, take(s, p).Allowed)
+
+		// Two windows later everything has expired
+		clock = clock.Add(2 * time.Minute)
+		assert.Equal(t, 3, take(s, p).Remaining)
+	})
+
+	t.Run("it should sweep idle keys", func(t *testing.T) {
+		s := newStore()
+		p := RateLimitPolicy{Requests: 1, Window: time.Second}
+
+		take(s, p)
+
+		shard := &s.shards[fnv32("k")%rateLimitShards]
+		shard.sweep(clock.Add(time.Hour), p.Window)
+		assert.NotContains(t, shard.entries, "k")
+	})
+}
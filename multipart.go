@@ -0,0 +1,370 @@
+package golly
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+)
+
+var (
+	ErrBodyTooLarge       = errors.New("request body too large")
+	ErrPartTooLarge       = errors.New("multipart part too large")
+	ErrTooManyParts       = errors.New("too many multipart parts")
+	ErrMalformedMultipart = errors.New("malformed multipart body")
+)
+
+const (
+	defaultMaxFileSize  = 32 << 20
+	defaultMaxTotalSize = 64 << 20
+	defaultMaxFieldSize = 1 << 20
+	defaultMaxMemory    = 1 << 20
+	defaultMaxParts     = 1000
+)
+
+// MultipartOptions limits multipart/form-data parsing. Zero values use the
+// defaults noted on each field.
+type MultipartOptions struct {
+	// MaxFileSize caps each file part (default 32 MiB)
+	MaxFileSize int64
+
+	// MaxTotalSize caps the whole request body (default 64 MiB)
+	MaxTotalSize int64
+
+	// MaxFieldSize caps each non-file part (default 1 MiB)
+	MaxFieldSize int64
+
+	// MaxMemory is how much of a file MultipartForm keeps in memory before
+	// spilling it to a temp file (default 1 MiB)
+	MaxMemory int64
+
+	// MaxParts caps the number of parts (default 1000)
+	MaxParts int
+
+	// TempDir receives spilled files (default os.TempDir())
+	TempDir string
+}
+
+func (o MultipartOptions) withDefaults() MultipartOptions {
+	if o.MaxFileSize <= 0 {
+		o.MaxFileSize = defaultMaxFileSize
+	}
+	if o.MaxTotalSize <= 0 {
+		o.MaxTotalSize = defaultMaxTotalSize
+	}
+	if o.MaxFieldSize <= 0 {
+		o.MaxFieldSize = defaultMaxFieldSize
+	}
+	if o.MaxMemory <= 0 {
+		o.MaxMemory = defaultMaxMemory
+	}
+	if o.MaxParts <= 0 {
+		o.MaxParts = defaultMaxParts
+	}
+	return o
+}
+
+func multipartOptions(opts []MultipartOptions) MultipartOptions {
+	if len(opts) > 0 {
+		return opts[0].withDefaults()
+	}
+	return MultipartOptions{}.withDefaults()
+}
+
+// MultipartPart is a single part of a multipart/form-data body. Reads are
+// capped at MaxFileSize for files and MaxFieldSize for fields; going past
+// the cap fails with a 413 *Error. A part is only valid until the
+// iteration moves on.
+type MultipartPart struct {
+	// Name is the form field name
+	Name string
+
+	// Filename is set for file parts
+	Filename string
+
+	// ContentType of the part, empty when the client did not send one
+	ContentType string
+
+	Header textproto.MIMEHeader
+
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+// IsFile reports whether the part carries a file.
+func (p *MultipartPart) IsFile() bool { return p.Filename != "" }
+
+// Read implements io.Reader over the part content.
+func (p *MultipartPart) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+
+	if p.read > p.limit {
+		n -= int(p.read - p.limit)
+		return max(n, 0), partTooLarge(p.Name, p.limit)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return n, multipartError(err)
+	}
+	return n, err
+}
+
+// Value reads a field part into a string.
+func (p *MultipartPart) Value() (string, error) {
+	b, err := io.ReadAll(p)
+	return string(b), err
+}
+
+// MultipartParts iterates the parts of a multipart/form-data request
+// body without buffering it: each file can be streamed straight to its
+// destination. Iteration stops at the first error, which is an *Error: 415
+// for other content types, 413 when a limit is exceeded and 400 for a
+// malformed body.
+//
+// Example:
+//
+//	for part, err := range wctx.MultipartParts(golly.MultipartOptions{MaxFileSize: 1 << 30}) {
+//	    if err != nil {
+//	        wctx.RenderError(err)
+//	        return
+//	    }
+//	    if part.IsFile() {
+//	        if err := store.Put(wctx.Context(), part.Filename, part); err != nil { ... }
+//	    }
+//	}
+func (wctx *WebContext) MultipartParts(opts ...MultipartOptions) iter.Seq2[*MultipartPart, error] {
+	opt := multipartOptions(opts)
+
+	return func(yield func(*MultipartPart, error) bool) {
+		mr, err := wctx.multipartReader(opt)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for count := 0; ; count++ {
+			p, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(nil, multipartError(err))
+				return
+			}
+
+			if count >= opt.MaxParts {
+				p.Close()
+				yield(nil, NewError(http.StatusRequestEntityTooLarge, ErrTooManyParts, map[string]any{"limit": opt.MaxParts}))
+				return
+			}
+
+			part := &MultipartPart{
+				Name:        p.FormName(),
+				Filename:    p.FileName(),
+				ContentType: p.Header.Get("Content-Type"),
+				Header:      p.Header,
+				r:           p,
+				limit:       opt.MaxFieldSize,
+			}
+			if part.IsFile() {
+				part.limit = opt.MaxFileSize
+			}
+
+			cont := yield(part, nil)
+			p.Close()
+			if !cont {
+				return
+			}
+		}
+	}
+}
+
+// multipartReader checks the content type and caps the body at
+// MaxTotalSize.
+func (wctx *WebContext) multipartReader(opt MultipartOptions) (*multipart.Reader, error) {
+	mediaType, params, err := mime.ParseMediaType(wctx.request.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		return nil, NewError(http.StatusUnsupportedMediaType, ErrUnsupportedContentType, map[string]any{"content_type": mediaType})
+	}
+
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, NewError(http.StatusBadRequest, ErrMalformedMultipart)
+	}
+
+	body := wctx.request.Body
+	if body == nil {
+		body = http.NoBody
+	}
+	return multipart.NewReader(&maxBytesReader{r: body, limit: opt.MaxTotalSize}, boundary), nil
+}
+
+// MultipartForm is a fully read multipart/form-data body. Files past
+// MaxMemory live in temp files that are removed when the request ends, or
+// earlier through RemoveAll.
+type MultipartForm struct {
+	Values map[string][]string
+	Files  map[string][]*UploadedFile
+}
+
+// Value returns the first value of a field, or "".
+func (f *MultipartForm) Value(name string) string {
+	if v := f.Values[name]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// File returns the first file of a field, or nil.
+func (f *MultipartForm) File(name string) *UploadedFile {
+	if v := f.Files[name]; len(v) > 0 {
+		return v[0]
+	}
+	return nil
+}
+
+// RemoveAll deletes the temp files backing the form.
+func (f *MultipartForm) RemoveAll() error {
+	var errs []error
+	for _, files := range f.Files {
+		for _, file := range files {
+			if file.path == "" {
+				continue
+			}
+			if err := os.Remove(file.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UploadedFile is a file part kept by MultipartForm, in memory or in a
+// temp file.
+type UploadedFile struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	Header      textproto.MIMEHeader
+
+	data []byte
+	path string
+}
+
+// Open returns the file content. Close it when done.
+func (f *UploadedFile) Open() (multipart.File, error) {
+	if f.path != "" {
+		return os.Open(f.path)
+	}
+	return memoryFile{bytes.NewReader(f.data)}, nil
+}
+
+type memoryFile struct{ *bytes.Reader }
+
+func (memoryFile) Close() error { return nil }
+
+// MultipartForm reads the whole multipart/form-data body with the limits of
+// opts, keeping small files in memory and spilling larger ones to temp
+// files. Errors are the *Errors described on MultipartParts.
+//
+// Example:
+//
+//	form, err := wctx.MultipartForm()
+//	if err != nil {
+//	    wctx.RenderError(err)
+//	    return
+//	}
+//	avatar := form.File("avatar")
+func (wctx *WebContext) MultipartForm(opts ...MultipartOptions) (*MultipartForm, error) {
+	opt := multipartOptions(opts)
+	form := &MultipartForm{
+		Values: map[string][]string{},
+		Files:  map[string][]*UploadedFile{},
+	}
+
+	for part, err := range wctx.MultipartParts(opt) {
+		if err != nil {
+			form.RemoveAll() //nolint:errcheck
+			return nil, err
+		}
+
+		if !part.IsFile() {
+			v, err := part.Value()
+			if err != nil {
+				form.RemoveAll() //nolint:errcheck
+				return nil, err
+			}
+			form.Values[part.Name] = append(form.Values[part.Name], v)
+			continue
+		}
+
+		file, err := saveUpload(part, opt)
+		if file != nil {
+			form.Files[part.Name] = append(form.Files[part.Name], file)
+		}
+		if err != nil {
+			form.RemoveAll() //nolint:errcheck
+			return nil, err
+		}
+	}
+
+	if wctx.ctx != nil {
+		context.AfterFunc(wctx.ctx, func() { form.RemoveAll() }) //nolint:errcheck
+	}
+	return form, nil
+}
+
+// saveUpload keeps up to MaxMemory bytes of part in memory and spills the
+// rest to a temp file. A file is returned whenever a temp file was
+// created, so the caller can clean it up on error.
+func saveUpload(part *MultipartPart, opt MultipartOptions) (*UploadedFile, error) {
+	file := &UploadedFile{
+		Filename:    part.Filename,
+		ContentType: part.ContentType,
+		Header:      part.Header,
+	}
+
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, part, opt.MaxMemory+1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if n <= opt.MaxMemory {
+		file.data, file.Size = buf.Bytes(), n
+		return file, nil
+	}
+
+	tmp, err := os.CreateTemp(opt.TempDir, "golly-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer tmp.Close()
+	file.path = tmp.Name()
+
+	written, err := io.Copy(tmp, io.MultiReader(&buf, part))
+	file.Size = written
+	return file, err
+}
+
+// multipartError maps a read failure to an *Error: limit errors pass
+// through, anything else means the body is malformed.
+func multipartError(err error) error {
+	var gerr *Error
+	if errors.As(err, &gerr) {
+		return gerr
+	}
+	return NewError(http.StatusBadRequest, fmt.Errorf("%w: %v", ErrMalformedMultipart, err))
+}
+
+func partTooLarge(name string, limit int64) error {
+	return NewError(http.StatusRequestEntityTooLarge, ErrPartTooLarge, map[string]any{"field": name, "limit": limit})
+}
@@ -0,0 +1,232 @@
+package golly
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type multipartEntry struct {
+	name, filename, content string
+}
+
+func multipartRequest(t *testing.T, entries ...multipartEntry) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	for _, e := range entries {
+		var (
+			w   io.Writer
+			err error
+		)
+		if e.filename != "" {
+			w, err = mw.CreateFormFile(e.name, e.filename)
+		} else {
+			w, err = mw.CreateFormField(e.name)
+		}
+		require.NoError(t, err)
+
+		_, err = io.WriteString(w, e.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func errorStatus(err error) int {
+	var gerr *Error
+	if errors.As(err, &gerr) {
+		return gerr.Status()
+	}
+	return 0
+}
+
+func TestMultipartParts(t *testing.T) {
+	t.Run("it should stream fields and files in order", func(t *testing.T) {
+		req := multipartRequest(t,
+			multipartEntry{name: "title", content: "Holiday"},
+			multipartEntry{name: "photo", filename: "beach.jpg", content: "jpeg-bytes"},
+		)
+		wctx := NewTestWebContext(req, httptest.NewRecorder())
+
+		var got []string
+		for part, err := range wctx.MultipartParts() {
+			require.NoError(t, err)
+
+			b, err := io.ReadAll(part)
+			require.NoError(t, err)
+
+			got = append(got, part.Name+"|"+part.Filename+"|"+string(b))
+			if part.IsFile() {
+				assert.Equal(t, "application/octet-stream", part.ContentType)
+			}
+		}
+
+		assert.Equal(t, []string{"title||Holiday", "photo|beach.jpg|jpeg-bytes"}, got)
+	})
+
+	t.Run("it should enforce the per-file limit", func(t *testing.T) {
+		req := multipartRequest(t, multipartEntry{name: "doc", filename: "big.bin", content: strings.Repeat("x", 100)})
+		wctx := NewTestWebContext(req, httptest.NewRecorder())
+
+		for part, err := range wctx.MultipartParts(MultipartOptions{MaxFileSize: 10}) {
+			require.NoError(t, err)
+
+			_, err = io.Copy(io.Discard, part)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, errorStatus(err))
+			assert.ErrorIs(t, err, ErrPartTooLarge)
+			break
+		}
+	})
+
+	t.Run("it should enforce the field limit", func(t *testing.T) {
+		req := multipartRequest(t, multipartEntry{name: "note", content: strings.Repeat("x", 100)})
+		wctx := NewTestWebContext(req, httptest.NewRecorder())
+
+		for part, err := range wctx.MultipartParts(MultipartOptions{MaxFieldSize: 99}) {
+			require.NoError(t, err)
+
+			_, err = part.Value()
+			assert.ErrorIs(t, err, ErrPartTooLarge)
+			break
+		}
+	})
+
+	t.Run("it should enforce the total limit", func(t *testing.T) {
+		req := multipartRequest(t,
+			multipartEntry{name: "a", filename: "a.txt", content: strings.Repeat("a", 600)},
+			multipartEntry{name: "b", filename: "b.txt", content: strings.Repeat("b", 600)},
+		)
+		wctx := NewTestWebContext(req, httptest.NewRecorder())
+
+		var failure error
+		for part, err := range wctx.MultipartParts(MultipartOptions{MaxTotalSize: 1000}) {
+			if err != nil {
+				failure = err
+				break
+			}
+			if _, err := io.Copy(io.Discard, part); err != nil {
+				failure = err
+				break
+			}
+		}
+
+		assert.ErrorIs(t, failure, ErrBodyTooLarge)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, errorStatus(failure))
+	})
+
+	t.Run("it should cap the number of parts", func(t *testing.T) {
+		req := multipartRequest(t, multipartEntry{name: "a"}, multipartEntry{name: "b"}, multipartEntry{name: "c"})
+		wctx := NewTestWebContext(req, httptest.NewRecorder())
+
+		var failure error
+		for _, err := range wctx.MultipartParts(MultipartOptions{MaxParts: 2}) {
+			failure = err
+		}
+		assert.ErrorIs(t, failure, ErrTooManyParts)
+	})
+
+	t.Run("it should reject other content types and malformed bodies", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		for _, err := range NewTestWebContext(req, httptest.NewRecorder()).MultipartParts() {
+			assert.Equal(t, http.StatusUnsupportedMediaType, errorStatus(err))
+		}
+
+		req = httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("garbage"))
+		req.Header.Set("Content-Type", "multipart/form-data; boundary=xyz")
+
+		for _, err := range NewTestWebContext(req, httptest.NewRecorder()).MultipartParts() {
+			assert.ErrorIs(t, err, ErrMalformedMultipart)
+			assert.Equal(t, http.StatusBadRequest, errorStatus(err))
+		}
+	})
+}
+
+func TestMultipartForm(t *testing.T) {
+	t.Run("it should collect fields and small files in memory", func(t *testing.T) {
+		req := multipartRequest(t,
+			multipartEntry{name: "tag", content: "a"},
+			multipartEntry{name: "tag", content: "b"},
+			multipartEntry{name: "avatar", filename: "me.png", content: "png-bytes"},
+		)
+
+		form, err := NewTestWebContext(req, httptest.NewRecorder()).MultipartForm()
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"a", "b"}, form.Values["tag"])
+		assert.Equal(t, "a", form.Value("tag"))
+
+		avatar := form.File("avatar")
+		require.NotNil(t, avatar)
+		assert.Equal(t, "me.png", avatar.Filename)
+		assert.Equal(t, int64(9), avatar.Size)
+		assert.Empty(t, avatar.path)
+
+		f, err := avatar.Open()
+		require.NoError(t, err)
+		b, _ := io.ReadAll(f)
+		assert.Equal(t, "png-bytes", string(b))
+		assert.NoError(t, f.Close())
+	})
+
+	t.Run("it should spill large files to temp files removed with the request", func(t *testing.T) {
+		content := strings.Repeat("z", 4096)
+		req := multipartRequest(t, multipartEntry{name: "doc", filename: "big.txt", content: content})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		wctx := NewWebContext(ctx, req, httptest.NewRecorder())
+
+		form, err := wctx.MultipartForm(MultipartOptions{MaxMemory: 1024, TempDir: t.TempDir()})
+		require.NoError(t, err)
+
+		doc := form.File("doc")
+		require.NotNil(t, doc)
+		require.NotEmpty(t, doc.path)
+		assert.Equal(t, int64(len(content)), doc.Size)
+
+		f, err := doc.Open()
+		require.NoError(t, err)
+		b, _ := io.ReadAll(f)
+		f.Close()
+		assert.Equal(t, content, string(b))
+
+		cancel()
+		assert.Eventually(t, func() bool {
+			_, err := os.Stat(doc.path)
+			return os.IsNotExist(err)
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("it should clean up spilled files when a limit fails", func(t *testing.T) {
+		dir := t.TempDir()
+		req := multipartRequest(t,
+			multipartEntry{name: "a", filename: "a.txt", content: strings.Repeat("a", 2048)},
+			multipartEntry{name: "b", filename: "b.txt", content: strings.Repeat("b", 8192)},
+		)
+
+		_, err := NewTestWebContext(req, httptest.NewRecorder()).MultipartForm(MultipartOptions{MaxMemory: 1024, MaxFileSize: 4096, TempDir: dir})
+		assert.ErrorIs(t, err, ErrPartTooLarge)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,154 @@
+package golly
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type webContextKey struct{}
+
+// WebContextFromContext returns the WebContext of a request handed to an
+// http.Handler through WrapHandler, WrapMiddleware, Route.Handle or
+// Route.MountHandler, or nil.
+//
+// Example:
+//
+//	func metrics(w http.ResponseWriter, r *http.Request) {
+//	    wctx := golly.WebContextFromContext(r.Context())
+//	    wctx.Logger().Info("scraped")
+//	}
+func WebContextFromContext(ctx context.Context) *WebContext {
+	if ctx == nil {
+		return nil
+	}
+
+	wctx, _ := ctx.Value(webContextKey{}).(*WebContext)
+	return wctx
+}
+
+// WrapHandler adapts an http.Handler to a HandlerFunc. The handler writes
+// through the WebContext response writer and gets a request whose
+// context is the golly Context (so ToGollyContext, IdentityFromContext and
+// WebContextFromContext work) and whose PathValue returns the route vars.
+//
+// Example:
+//
+//	app.Routes().Get("/metrics", golly.WrapHandler(promhttp.Handler()))
+func WrapHandler(h http.Handler) HandlerFunc {
+	return func(wctx *WebContext) { wctx.serveHTTP(h) }
+}
+
+// WrapHandlerFunc is WrapHandler for a plain function.
+func WrapHandlerFunc(f http.HandlerFunc) HandlerFunc {
+	return WrapHandler(f)
+}
+
+// WrapMiddleware adapts net/http middleware to a MiddlewareFunc. The
+// pooled WebContext is carried through the middleware and handed to the
+// next golly handler with the request, context and response writer the
+// middleware passed on; the originals are restored once it returns.
+// Middleware that swaps the request context for one not derived from it
+// gets a fresh WebContext without route vars.
+//
+// Example:
+//
+//	app.Routes().Use(golly.WrapMiddleware(authproxy.Middleware))
+func WrapMiddleware(mw func(http.Handler) http.Handler) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			wctx := WebContextFromContext(r.Context())
+			if wctx == nil {
+				wctx = NewWebContext(r.Context(), r, w)
+				next(wctx)
+				return
+			}
+
+			restore := wctx.adopt(w, r)
+			defer restore()
+
+			next(wctx)
+		}))
+
+		return func(wctx *WebContext) { wctx.serveHTTP(h) }
+	}
+}
+
+// Handle mounts h at path for every method.
+//
+// Example:
+//
+//	app.Routes().Handle("/debug/vars", expvar.Handler())
+func (re *Route) Handle(path string, h http.Handler) *Route {
+	return re.Add(path, WrapHandler(h), ALL)
+}
+
+// MountHandler mounts h for every method at prefix and everything below
+// it. The request path is passed on unchanged; wrap h in http.StripPrefix
+// when it expects paths relative to the mount.
+//
+// Example:
+//
+//	app.Routes().MountHandler("/debug/pprof", http.HandlerFunc(pprof.Index))
+func (re *Route) MountHandler(prefix string, h http.Handler) *Route {
+	prefix = strings.TrimSuffix(prefix, "/")
+	root := prefix
+	if root == "" {
+		root = "/"
+	}
+
+	wrapped := WrapHandler(h)
+
+	return re.
+		Add(root, wrapped, ALL).
+		Add(prefix+"/*", wrapped, ALL)
+}
+
+// serveHTTP runs h with a request carrying wctx in its context and the
+// route vars as path values, restoring the request and context after.
+func (wctx *WebContext) serveHTTP(h http.Handler) {
+	req, ctx := wctx.request, wctx.ctx
+	defer func() { wctx.request, wctx.ctx = req, ctx }()
+
+	wctx.ctx = WithValue(ctx, webContextKey{}, wctx)
+	wctx.request = req.WithContext(wctx.ctx)
+
+	for key, value := range wctx.URLParams().All() {
+		wctx.request.SetPathValue(key, value)
+	}
+
+	h.ServeHTTP(wctx.writer, wctx.request)
+}
+
+// adopt points wctx at the request and writer net/http middleware passed
+// on and returns a func restoring the previous ones.
+func (wctx *WebContext) adopt(w http.ResponseWriter, r *http.Request) func() {
+	req, writer, ctx := wctx.request, wctx.writer, wctx.ctx
+
+	wctx.request = r
+
+	// Values added by the middleware must be visible from wctx.Context()
+	if rctx := r.Context(); rctx != context.Context(ctx) {
+		c := NewContext(rctx)
+		c.application = ctx.Application()
+		c.fields = ctx.collectFields(nil)
+		wctx.ctx = c
+	}
+
+	var wrapped WrapResponseWriter
+	if w != writer {
+		if ww, ok := w.(WrapResponseWriter); ok {
+			wctx.writer = ww
+		} else {
+			wrapped = NewWrapResponseWriter(w, r.ProtoMajor)
+			wctx.writer = wrapped
+		}
+	}
+
+	return func() {
+		wctx.request, wctx.writer, wctx.ctx = req, writer, ctx
+		if wrapped != nil {
+			FreeWrapResponseWriter(wrapped)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package golly
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stdKey struct{}
+
+func TestNetHTTPInterop(t *testing.T) {
+	t.Run("it should expose route vars and the WebContext to wrapped handlers", func(t *testing.T) {
+		var seen *WebContext
+
+		rec := serveTestApp(t, Options{}, func(r *Route) {
+			r.Get("/users/{id}", WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = WebContextFromContext(r.Context())
+				assert.NotNil(t, ToGollyContext(r.Context()).Application())
+
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte("user " + r.PathValue("id")))
+			}))
+		}, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, "user 42", rec.Body.String())
+		require.NotNil(t, seen)
+	})
+
+	t.Run("it should mount handlers at a path and a prefix for every method", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Method + " " + r.URL.Path + " " + r.PathValue(CatchAllKey)))
+		})
+
+		setup := func(r *Route) {
+			r.Handle("/health", h)
+			r.Namespace("/debug", func(r *Route) {
+				r.MountHandler("/pprof", h)
+			})
+		}
+
+		rec := serveTestApp(t, Options{}, setup, httptest.NewRequest(http.MethodPost, "/health", nil))
+		assert.Equal(t, "POST /health ", rec.Body.String())
+
+		rec = serveTestApp(t, Options{}, setup, httptest.NewRequest(http.MethodGet, "/debug/pprof", nil))
+		assert.Equal(t, "GET /debug/pprof ", rec.Body.String())
+
+		rec = serveTestApp(t, Options{}, setup, httptest.NewRequest(http.MethodDelete, "/debug/pprof/heap/1", nil))
+		assert.Equal(t, "DELETE /debug/pprof/heap/1 heap/1", rec.Body.String())
+
+		rec = serveTestApp(t, Options{}, setup, httptest.NewRequest(http.MethodGet, "/debug/other", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("it should carry the pooled WebContext through std middleware", func(t *testing.T) {
+		std := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Std", "1")
+				ctx := context.WithValue(r.Context(), stdKey{}, "from-std")
+				next.ServeHTTP(w, r.WithContext(ctx))
+			})
+		}
+
+		var (
+			outer, inner *WebContext
+			value        any
+			id           string
+		)
+
+		rec := serveTestApp(t, Options{}, func(r *Route) {
+			r.Use(func(next HandlerFunc) HandlerFunc {
+				return func(wctx *WebContext) {
+					outer = wctx
+					next(wctx)
+
+					// Restored once the std middleware returns
+					assert.Nil(t, wctx.Context().Value(stdKey{}))
+				}
+			})
+			r.Use(WrapMiddleware(std))
+			r.Get("/items/{id}", func(wctx *WebContext) {
+				inner = wctx
+				value = wctx.Context().Value(stdKey{})
+				id = wctx.URLParams().Get("id")
+				wctx.RenderText("ok")
+			})
+		}, httptest.NewRequest(http.MethodGet, "/items/7", nil))
+
+		assert.Equal(t, "ok", rec.Body.String())
+		assert.Equal(t, "1", rec.Header().Get("X-Std"))
+		assert.Same(t, outer, inner)
+		assert.Equal(t, "from-std", value)
+		assert.Equal(t, "7", id)
+	})
+
+	t.Run("it should write through a response writer the middleware swapped in", func(t *testing.T) {
+		var status int
+
+		std := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				rec := &statusRecorder{ResponseWriter: w}
+				next.ServeHTTP(rec, r)
+				status = rec.status
+			})
+		}
+
+		rec := serveTestApp(t, Options{}, func(r *Route) {
+			r.Use(WrapMiddleware(std))
+			r.Get("/", func(wctx *WebContext) {
+				wctx.WithStatus(http.StatusCreated).RenderText("made")
+			})
+		}, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "made", rec.Body.String())
+		assert.Equal(t, http.StatusCreated, status)
+	})
+
+	t.Run("it should short-circuit when the middleware does not call next", func(t *testing.T) {
+		called := false
+		deny := func(http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "nope", http.StatusUnauthorized)
+			})
+		}
+
+		rec := serveTestApp(t, Options{}, func(r *Route) {
+			r.Use(WrapMiddleware(deny))
+			r.Get("/", func(wctx *WebContext) { called = true })
+		}, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.False(t, called)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,545 @@
+package golly
+
+import (
+	"encoding"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// OpenAPIVersion is the OpenAPI specification version emitted by Route.OpenAPI.
+const OpenAPIVersion = "3.1.0"
+
+// OpenAPIInfo is the info object of an OpenAPI document.
+type OpenAPIInfo struct {
+	Title       string `json:"title" yaml:"title"`
+	Version     string `json:"version" yaml:"version"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// OpenAPIDocument is the subset of an OpenAPI 3.1 document golly generates
+// from registered routes and their RouteDoc metadata.
+type OpenAPIDocument struct {
+	OpenAPI    string                     `json:"openapi" yaml:"openapi"`
+	Info       OpenAPIInfo                `json:"info" yaml:"info"`
+	Paths      map[string]OpenAPIPathItem `json:"paths" yaml:"paths"`
+	Components *OpenAPIComponents         `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+// OpenAPIPathItem maps lower-case HTTP methods to operations.
+type OpenAPIPathItem map[string]*OpenAPIOperation
+
+// OpenAPIOperation describes a single method on a path.
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Summary     string                     `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses" yaml:"responses"`
+}
+
+// OpenAPIParameter is a path, query or header parameter.
+type OpenAPIParameter struct {
+	Name     string      `json:"name" yaml:"name"`
+	In       string      `json:"in" yaml:"in"`
+	Required bool        `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema   *JSONSchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// OpenAPIRequestBody describes the request payload.
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty" yaml:"required,omitempty"`
+	Content  map[string]OpenAPIMediaType `json:"content" yaml:"content"`
+}
+
+// OpenAPIResponse describes a response payload.
+type OpenAPIResponse struct {
+	Description string                      `json:"description" yaml:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// OpenAPIMediaType binds a schema to a content type.
+type OpenAPIMediaType struct {
+	Schema *JSONSchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// OpenAPIComponents holds the named schemas referenced through $ref.
+type OpenAPIComponents struct {
+	Schemas map[string]*JSONSchema `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+}
+
+// JSONSchema is the subset of JSON Schema (2020-12) used for OpenAPI 3.1.
+type JSONSchema struct {
+	Ref                  string                 `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string                 `json:"format,omitempty" yaml:"format,omitempty"`
+	Description          string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Required             []string               `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+// openAPIWildcard names the anonymous "*" catch-all in OpenAPI paths, where
+// "*" is not a valid template name.
+const openAPIWildcard = "wildcard"
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	uuidType          = reflect.TypeFor[UUID]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+
+	componentNameReplacer = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// OpenAPI builds an OpenAPI 3.1 document from the routes below (and
+// including) this route. Path parameters come from dynamic tokens, query
+// parameters, request bodies and responses from the RouteDoc Query, Input
+// and Output types. Named struct types become components referenced via
+// $ref. Host trees are not included, as OpenAPI paths cannot tell hosts apart.
+//
+// Struct fields follow encoding/json naming. A field is required unless it
+// is tagged omitempty/omitzero; required:"true" or validate:"required"
+// always mark it required.
+func (re *Route) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	if info.Title == "" {
+		info.Title = "API"
+	}
+	if info.Version == "" {
+		info.Version = "0.0.0"
+	}
+
+	doc := &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info:    info,
+		Paths:   map[string]OpenAPIPathItem{},
+	}
+
+	sb := newSchemaBuilder()
+
+	for _, ri := range re.Table() {
+		if ri.Host != "" {
+			continue
+		}
+
+		method := strings.ToLower(ri.Method)
+		if method == "connect" {
+			continue // not an OpenAPI operation
+		}
+
+		path := openAPIPath(ri.Path)
+		item := doc.Paths[path]
+		if item == nil {
+			item = OpenAPIPathItem{}
+			doc.Paths[path] = item
+		}
+
+		item[method] = sb.operation(ri)
+	}
+
+	if len(sb.components) > 0 {
+		doc.Components = &OpenAPIComponents{Schemas: sb.components}
+	}
+
+	return doc
+}
+
+// OpenAPIHandler serves the OpenAPI document of the application routes as
+// JSON. The document is built on the first request.
+//
+// Example:
+//
+//	app.Routes().Get("/openapi.json", golly.OpenAPIHandler(golly.OpenAPIInfo{
+//	    Title:   "Orders",
+//	    Version: "1.4.0",
+//	}))
+func OpenAPIHandler(info OpenAPIInfo) HandlerFunc {
+	var (
+		once sync.Once
+		doc  *OpenAPIDocument
+	)
+
+	return func(wctx *WebContext) {
+		once.Do(func() { doc = wctx.route.appRoot().OpenAPI(info) })
+		wctx.RenderJSON(doc)
+	}
+}
+
+// openAPIPath rewrites a golly pattern into an OpenAPI path template,
+// dropping matchers and catch-all markers.
+func openAPIPath(pattern string) string {
+	var b strings.Builder
+	for _, token := range tokenize(pattern) {
+		if token.value == "/" && !token.isDynamic {
+			continue
+		}
+
+		b.WriteByte('/')
+		if !token.isDynamic {
+			b.WriteString(token.value)
+			continue
+		}
+
+		b.WriteByte('{')
+		b.WriteString(openAPIParamName(token.value))
+		b.WriteByte('}')
+	}
+
+	if b.Len() == 0 {
+		return "/"
+	}
+	return b.String()
+}
+
+func openAPIParamName(name string) string {
+	if name == CatchAllKey {
+		return openAPIWildcard
+	}
+	return name
+}
+
+// varSchema maps a path token matcher onto a schema.
+func varSchema(v RouteVarInfo) *JSONSchema {
+	if v.CatchAll {
+		return &JSONSchema{Type: "string"}
+	}
+
+	switch v.Matcher {
+	case "":
+		return &JSONSchema{Type: "string"}
+	case "int":
+		return &JSONSchema{Type: "integer"}
+	case "uint":
+		zero := 0.0
+		return &JSONSchema{Type: "integer", Minimum: &zero}
+	case "uuid":
+		return &JSONSchema{Type: "string", Format: "uuid"}
+	case "alpha":
+		return &JSONSchema{Type: "string", Pattern: "^[A-Za-z]+$"}
+	case "alphanum":
+		return &JSONSchema{Type: "string", Pattern: "^[A-Za-z0-9]+$"}
+	case "slug":
+		return &JSONSchema{Type: "string", Pattern: "^[a-z0-9]+(?:-[a-z0-9]+)*$"}
+	}
+
+	if _, ok := lookupRouteConstraint(v.Matcher); ok {
+		return &JSONSchema{Type: "string"} // custom constraint, opaque to us
+	}
+	return &JSONSchema{Type: "string", Pattern: "^(?:" + v.Matcher + ")$"}
+}
+
+// schemaBuilder converts Go types to JSON schemas, collecting named struct
+// types as components so recursive types terminate.
+type schemaBuilder struct {
+	components map[string]*JSONSchema
+	names      map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		components: map[string]*JSONSchema{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+func (sb *schemaBuilder) operation(ri RouteInfo) *OpenAPIOperation {
+	op := &OpenAPIOperation{
+		OperationID: ri.Name,
+		Summary:     ri.Description,
+		Responses:   map[string]OpenAPIResponse{},
+	}
+
+	for _, v := range ri.Vars {
+		op.Parameters = append(op.Parameters, OpenAPIParameter{
+			Name:     openAPIParamName(v.Name),
+			In:       "path",
+			Required: true,
+			Schema:   varSchema(v),
+		})
+	}
+
+	doc := ri.Doc
+	if doc != nil && doc.query != nil {
+		op.Parameters = sb.parameters(op.Parameters, doc.query, ParamSourceQuery)
+	}
+
+	if doc != nil && doc.input != nil {
+		body := true
+		if doc.input.Kind() == reflect.Struct {
+			op.Parameters = sb.parameters(op.Parameters, doc.input, ParamSourceInput)
+			body = slices.ContainsFunc(structFields(doc.input, ParamSourceInput), func(f structField) bool {
+				return f.source == ParamSourceInput
+			})
+		}
+
+		if body {
+			op.RequestBody = &OpenAPIRequestBody{
+				Required: true,
+				Content:  jsonContent(sb.schema(doc.input)),
+			}
+		}
+	}
+
+	res := OpenAPIResponse{Description: http.StatusText(http.StatusOK)}
+	if doc != nil && doc.output != nil {
+		res.Content = jsonContent(sb.schema(doc.output))
+	}
+	op.Responses["200"] = res
+
+	return op
+}
+
+// parameters appends the query and header fields of t. Path fields are
+// already described by the route tokens.
+func (sb *schemaBuilder) parameters(ret []OpenAPIParameter, t reflect.Type, source ParamSource) []OpenAPIParameter {
+	for _, f := range structFields(t, source) {
+		if f.source != ParamSourceQuery && f.source != ParamSourceHeader {
+			continue
+		}
+
+		ret = append(ret, OpenAPIParameter{
+			Name:     f.name,
+			In:       string(f.source),
+			Required: isRouteParamRequired(f.field),
+			Schema:   sb.fieldSchema(f.field),
+		})
+	}
+	return ret
+}
+
+func jsonContent(s *JSONSchema) map[string]OpenAPIMediaType {
+	return map[string]OpenAPIMediaType{"application/json": {Schema: s}}
+}
+
+// schema returns the schema for t, or a $ref for named struct types.
+func (sb *schemaBuilder) schema(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	case t == uuidType:
+		return &JSONSchema{Type: "string", Format: "uuid"}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &JSONSchema{} // custom encoding, any value
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &JSONSchema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &JSONSchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &JSONSchema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &JSONSchema{Type: "integer", Minimum: &zero}
+	case reflect.Float32:
+		return &JSONSchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &JSONSchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string", Format: "byte"} // base64 per encoding/json
+		}
+		return &JSONSchema{Type: "array", Items: sb.schema(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: sb.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return sb.structSchema(t)
+		}
+		return &JSONSchema{Ref: "#/components/schemas/" + sb.component(t)}
+	}
+
+	return &JSONSchema{}
+}
+
+// component registers a named struct type and returns its component name.
+func (sb *schemaBuilder) component(t reflect.Type) string {
+	if name, ok := sb.names[t]; ok {
+		return name
+	}
+
+	name := componentNameReplacer.ReplaceAllString(t.Name(), "_")
+	if _, taken := sb.components[name]; taken {
+		pkg := t.PkgPath()
+		if i := strings.LastIndexByte(pkg, '/'); i >= 0 {
+			pkg = pkg[i+1:]
+		}
+		name = pkg + "." + name
+	}
+
+	// Register before recursing so self-referencing types resolve to a $ref
+	sb.names[t] = name
+	sb.components[name] = &JSONSchema{}
+	*sb.components[name] = *sb.structSchema(t)
+
+	return name
+}
+
+func (sb *schemaBuilder) structSchema(t reflect.Type) *JSONSchema {
+	s := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+	sb.addFields(s, t)
+	return s
+}
+
+// addFields adds the JSON-visible fields of t to s, flattening embedded
+// structs the way encoding/json does. Fields bound from the path, query or
+// headers are left out.
+func (sb *schemaBuilder) addFields(s *JSONSchema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		tag, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == "-" && opts == "" {
+			continue
+		}
+
+		if field.Anonymous && tag == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				sb.addFields(s, ft)
+				continue
+			}
+		}
+
+		if !field.IsExported() || isPinnedField(field) {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = sb.fieldSchema(field)
+
+		omit := hasTagOption(opts, "omitempty") || hasTagOption(opts, "omitzero")
+		if !omit || isRouteParamRequired(field) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// fieldSchema returns the schema of a struct field with its validation
+// rules applied, so the document matches what Validate enforces.
+func (sb *schemaBuilder) fieldSchema(field reflect.StructField) *JSONSchema {
+	s := sb.schema(field.Type)
+	applyRules(s, rulesOf(field), field.Type)
+	return s
+}
+
+func applyRules(s *JSONSchema, fr fieldRules, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	for _, r := range fr.rules {
+		switch r.name {
+		case "min", "max", "len":
+			if r.name != "max" {
+				setBound(s, t, r.num, true)
+			}
+			if r.name != "min" {
+				setBound(s, t, r.num, false)
+			}
+		case "oneof":
+			for _, opt := range r.oneOf {
+				if n, err := strconv.ParseFloat(opt, 64); err == nil && s.Type != "string" {
+					s.Enum = append(s.Enum, n)
+				} else {
+					s.Enum = append(s.Enum, opt)
+				}
+			}
+		case "email":
+			s.Format = "email"
+		case "regex":
+			s.Pattern = r.param
+		}
+	}
+
+	if fr.elem == nil {
+		return
+	}
+	switch {
+	case s.Items != nil:
+		applyRules(s.Items, *fr.elem, t.Elem())
+	case s.AdditionalProperties != nil:
+		applyRules(s.AdditionalProperties, *fr.elem, t.Elem())
+	}
+}
+
+// setBound maps a min (lower) or max bound onto the keyword matching t.
+func setBound(s *JSONSchema, t reflect.Type, n float64, lower bool) {
+	length := int(n)
+
+	switch t.Kind() {
+	case reflect.String:
+		if lower {
+			s.MinLength = &length
+		} else {
+			s.MaxLength = &length
+		}
+	case reflect.Slice, reflect.Array:
+		if lower {
+			s.MinItems = &length
+		} else {
+			s.MaxItems = &length
+		}
+	case reflect.Map:
+		// minProperties/maxProperties are not modelled
+	default:
+		if lower {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	}
+}
+
+// isPinnedField reports whether field is bound from a path, query or header tag.
+func isPinnedField(field reflect.StructField) bool {
+	for _, src := range paramTagSources {
+		if _, ok := field.Tag.Lookup(string(src)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var cur string
+		cur, opts, _ = strings.Cut(opts, ",")
+		if cur == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package golly
+
+import (
+	"bytes"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type openAPIAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type openAPIAudit struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type openAPIUser struct {
+	openAPIAudit
+
+	ID       int64             `json:"id"`
+	Name     string            `json:"name" validate:"required"`
+	Email    string            `json:"email,omitempty" required:"true"`
+	Tags     []string          `json:"tags,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Address  *openAPIAddress   `json:"address,omitempty"`
+	Manager  *openAPIUser      `json:"manager,omitempty"`
+	Avatar   []byte            `json:"avatar,omitempty"`
+	Password string            `json:"-"`
+	internal string
+}
+
+type openAPIListQuery struct {
+	Page   int    `query:"page"`
+	Search string `json:"q" validate:"required"`
+	Skip   string `query:"-"`
+}
+
+func TestRouteOpenAPI(t *testing.T) {
+	root := NewRouteRoot()
+	root.Namespace("/users", func(r *Route) {
+		r.Get("/", tableHandler, Describe("list users").Query(openAPIListQuery{}).Output([]openAPIUser{}))
+		r.Post("/", tableHandler, Input(openAPIUser{}).Output(openAPIUser{}).Name("users.create"))
+		r.Get("/{id:int}", tableHandler, Output(&openAPIUser{}))
+		r.Get("/{id:int}/files/{path...}", tableHandler)
+		r.Get("/{code:[a-f]+}/raw", tableHandler)
+	})
+	root.Host("admin.example.com", func(r *Route) {
+		r.Get("/hidden", tableHandler)
+	})
+
+	doc := root.OpenAPI(OpenAPIInfo{Title: "Users", Version: "1.0.0"})
+
+	t.Run("it should emit the document header", func(t *testing.T) {
+		assert.Equal(t, "3.1.0", doc.OpenAPI)
+		assert.Equal(t, "Users", doc.Info.Title)
+		assert.Equal(t, "1.0.0", doc.Info.Version)
+	})
+
+	t.Run("it should convert patterns to path templates", func(t *testing.T) {
+		var paths []string
+		for p := range doc.Paths {
+			paths = append(paths, p)
+		}
+
+		assert.ElementsMatch(t, []string{
+			"/users",
+			"/users/{id}",
+			"/users/{id}/files/{path}",
+			"/users/{code}/raw",
+		}, paths)
+	})
+
+	t.Run("it should describe path parameters from tokens", func(t *testing.T) {
+		op := doc.Paths["/users/{id}/files/{path}"]["get"]
+		require.NotNil(t, op)
+		require.Len(t, op.Parameters, 2)
+
+		assert.Equal(t, OpenAPIParameter{Name: "id", In: "path", Required: true, Schema: &JSONSchema{Type: "integer"}}, op.Parameters[0])
+		assert.Equal(t, OpenAPIParameter{Name: "path", In: "path", Required: true, Schema: &JSONSchema{Type: "string"}}, op.Parameters[1])
+
+		raw := doc.Paths["/users/{code}/raw"]["get"]
+		assert.Equal(t, "^(?:[a-f]+)$", raw.Parameters[0].Schema.Pattern)
+	})
+
+	t.Run("it should describe query parameters", func(t *testing.T) {
+		op := doc.Paths["/users"]["get"]
+		require.NotNil(t, op)
+
+		assert.Equal(t, "list users", op.Summary)
+		assert.Equal(t, []OpenAPIParameter{
+			{Name: "page", In: "query", Schema: &JSONSchema{Type: "integer", Format: "int64"}},
+			{Name: "q", In: "query", Required: true, Schema: &JSONSchema{Type: "string"}},
+		}, op.Parameters)
+
+		res := op.Responses["200"].Content["application/json"].Schema
+		assert.Equal(t, "array", res.Type)
+		assert.Equal(t, "#/components/schemas/openAPIUser", res.Items.Ref)
+	})
+
+	t.Run("it should describe request bodies", func(t *testing.T) {
+		op := doc.Paths["/users"]["post"]
+		require.NotNil(t, op)
+
+		assert.Equal(t, "users.create", op.OperationID)
+		require.NotNil(t, op.RequestBody)
+		assert.Equal(t, "#/components/schemas/openAPIUser", op.RequestBody.Content["application/json"].Schema.Ref)
+	})
+
+	t.Run("it should build component schemas", func(t *testing.T) {
+		require.NotNil(t, doc.Components)
+
+		user := doc.Components.Schemas["openAPIUser"]
+		require.NotNil(t, user)
+
+		assert.Equal(t, "object", user.Type)
+		assert.ElementsMatch(t, []string{"created_at", "id", "name", "email"}, user.Required)
+		assert.NotContains(t, user.Properties, "Password")
+		assert.NotContains(t, user.Properties, "internal")
+
+		assert.Equal(t, &JSONSchema{Type: "string", Format: "date-time"}, user.Properties["created_at"])
+		assert.Equal(t, &JSONSchema{Type: "array", Items: &JSONSchema{Type: "string"}}, user.Properties["tags"])
+		assert.Equal(t, &JSONSchema{Type: "object", AdditionalProperties: &JSONSchema{Type: "string"}}, user.Properties["labels"])
+		assert.Equal(t, &JSONSchema{Type: "string", Format: "byte"}, user.Properties["avatar"])
+		assert.Equal(t, "#/components/schemas/openAPIAddress", user.Properties["address"].Ref)
+		assert.Equal(t, "#/components/schemas/openAPIUser", user.Properties["manager"].Ref)
+
+		address := doc.Components.Schemas["openAPIAddress"]
+		require.NotNil(t, address)
+		assert.Equal(t, []string{"city"}, address.Required)
+	})
+
+	t.Run("it should split bound inputs into parameters and body", func(t *testing.T) {
+		root := NewRouteRoot()
+		root.Post("/orders/{id:int}", tableHandler, Input(bindOrderInput{}))
+
+		op := root.OpenAPI(OpenAPIInfo{}).Paths["/orders/{id}"]["post"]
+		require.NotNil(t, op)
+
+		in := map[string]string{}
+		for _, p := range op.Parameters {
+			in[p.Name] = p.In
+		}
+		assert.Equal(t, "path", in["id"])
+		assert.Equal(t, "header", in["X-Tenant"])
+		assert.Equal(t, "query", in["ref"])
+
+		require.NotNil(t, op.RequestBody)
+		body := root.OpenAPI(OpenAPIInfo{}).Components.Schemas["bindOrderInput"]
+		require.NotNil(t, body)
+		assert.ElementsMatch(t, []string{"name", "quantity", "price"}, slices.Collect(maps.Keys(body.Properties)))
+	})
+
+	t.Run("it should skip host trees", func(t *testing.T) {
+		assert.NotContains(t, doc.Paths, "/hidden")
+	})
+
+	t.Run("it should default info", func(t *testing.T) {
+		doc := NewRouteRoot().OpenAPI(OpenAPIInfo{})
+		assert.Equal(t, "API", doc.Info.Title)
+		assert.Equal(t, "0.0.0", doc.Info.Version)
+		assert.Nil(t, doc.Components)
+	})
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	app := NewApplication(Options{})
+	app.routes.Get("/users/{id:uuid}", tableHandler, Output(openAPIAddress{}))
+	app.routes.Get("/openapi.json", OpenAPIHandler(OpenAPIInfo{Title: "Test"}))
+
+	w := httptest.NewRecorder()
+	RouteRequest(app, httptest.NewRequest(http.MethodGet, "/openapi.json", nil), w)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var doc OpenAPIDocument
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+
+	assert.Equal(t, "Test", doc.Info.Title)
+	op := doc.Paths["/users/{id}"]["get"]
+	require.NotNil(t, op)
+	assert.Equal(t, &JSONSchema{Type: "string", Format: "uuid"}, op.Parameters[0].Schema)
+	assert.Contains(t, doc.Components.Schemas, "openAPIAddress")
+}
+
+func TestEncodeOpenAPI(t *testing.T) {
+	root := NewRouteRoot()
+	root.Get("/ping", tableHandler)
+	doc := root.OpenAPI(OpenAPIInfo{Title: "Ping", Version: "1"})
+
+	var out bytes.Buffer
+	require.NoError(t, encodeTo(&out, doc, "yaml"))
+	assert.Contains(t, out.String(), "openapi: 3.1.0")
+	assert.Contains(t, out.String(), "/ping:")
+
+	out.Reset()
+	require.NoError(t, encodeTo(&out, doc, "json"))
+	assert.Contains(t, out.String(), `"openapi": "3.1.0"`)
+
+	assert.Error(t, encodeTo(&out, doc, "xml"))
+}
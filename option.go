@@ -50,4 +50,20 @@ type Options struct {
 	Standalone bool
 
 	ShutdownWait time.Duration
+
+	// ValidateInput if true runs Validate on structs decoded by
+	// WebContext.Marshal and WebContext.Bind, returning its 422 error
+	ValidateInput bool
+
+	// ETags if true makes Render tag successful GET/HEAD responses with a
+	// strong ETag hashed from the body and answer matching conditional
+	// requests with 304 (see WebContext.CheckPreconditions)
+	ETags bool
+
+	// MaxBodySize caps request bodies in bytes, answering 413 past it
+	// (0 means no limit); WebContext.LimitBody overrides it per request
+	MaxBodySize int64
+
+	// JSON hardens request JSON decoding (unknown fields, nesting depth)
+	JSON JSONOptions
 }
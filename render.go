@@ -3,9 +3,14 @@ package golly
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"mime"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/segmentio/encoding/json"
 )
@@ -27,36 +32,68 @@ var (
 	FormatTypeAttachment FormatOption = 0x0010
 	FormatTypeHTML       FormatOption = 0x0020
 
+	// FormatTypeNegotiate picks the format from the request Accept header
+	// among the registered marshalers (see RenderNegotiated)
+	FormatTypeNegotiate FormatOption = 0x8000
+
 	ErrorInvalidType = fmt.Errorf("invalid data type provided")
 
-	marshalers = map[FormatOption]Marshaler{
+	marshalersMu sync.RWMutex
+	marshalers   = map[FormatOption]Marshaler{
 		FormatTypeJSON: {json.Marshal, "application/json"},
 		FormatTypeXML:  {xml.Marshal, "application/xml"},
 		FormatTypeData: {marshalContent, ""},
-		FormatTypeText: {marshalContent, "text/none"},
+		FormatTypeText: {marshalContent, "text/plain; charset=utf-8"},
 		FormatTypeHTML: {marshalContent, "text/html; charset=UTF-8"},
 	}
+
+	// marshalerOrder is the server preference used to break Accept ties
+	marshalerOrder = []FormatOption{FormatTypeJSON, FormatTypeXML, FormatTypeData, FormatTypeText, FormatTypeHTML}
 )
 
+// RegisterMarshaler registers (or replaces) the marshaler for a format.
+// New formats take part in negotiation after the built-in ones. Safe to
+// call concurrently with rendering.
 func RegisterMarshaler(tpe FormatOption, marshal marshalFunc, contentType string) {
+	marshalersMu.Lock()
+	defer marshalersMu.Unlock()
+
+	if _, ok := marshalers[tpe]; !ok {
+		marshalerOrder = append(marshalerOrder, tpe)
+	}
 	marshalers[tpe] = Marshaler{marshal, contentType}
 }
 
+func lookupMarshaler(tpe FormatOption) (Marshaler, bool) {
+	marshalersMu.RLock()
+	defer marshalersMu.RUnlock()
+
+	m, ok := marshalers[tpe]
+	return m, ok
+}
+
 func Render(wctx *WebContext, format FormatOption, res any) {
 	// Default format
 	if format == 0 {
 		format = FormatTypeJSON
 	}
 
+	if format == FormatTypeNegotiate {
+		renderNegotiated(wctx, res)
+		return
+	}
+
 	resp := wctx.Response()
 
-	// Handle HEAD requests early
-	if wctx.Request().Method == http.MethodHead {
+	// Handle HEAD requests early, unless the body is needed to answer
+	// conditional headers
+	head := wctx.Request().Method == http.MethodHead
+	if head && !wctx.conditionalRender() {
 		resp.WriteHeader(http.StatusOK)
 		return
 	}
 
-	marshal, hasMarshal := marshalers[format]
+	marshal, hasMarshal := lookupMarshaler(format)
 
 	// Prepare response body and status
 	status := http.StatusOK
@@ -96,17 +133,218 @@ func Render(wctx *WebContext, format FormatOption, res any) {
 		ct = http.DetectContentType(b)
 	}
 
+	// Answer conditional requests (ETag / Last-Modified) with 304 or 412
+	if status == http.StatusOK && wctx.renderPreconditions(b) {
+		return
+	}
+
 	// Set headers and write response
 	h := resp.Header()
 	h.Set("Content-Type", ct)
 	resp.WriteHeader(status)
 
+	if head {
+		return
+	}
+
 	// Write response body
 	if _, err := resp.Write(b); err != nil {
 		wctx.Logger().Errorf("Error writing response: %v", err)
 	}
 }
 
+// RenderError writes err as a JSON *Error (see AsError) with its status
+// code. Server errors are logged with their cause.
+func RenderError(wctx *WebContext, err error) {
+	gerr := AsError(err)
+	if gerr.Status() >= http.StatusInternalServerError {
+		wctx.Logger().Errorf("request failed: %v", err)
+	}
+
+	b, mErr := json.Marshal(gerr)
+	if mErr != nil {
+		wctx.Logger().Errorf("Marshaling error: %v", mErr)
+		wctx.Response().WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	h := wctx.ResponseHeaders()
+	h.Set("Content-Type", "application/json")
+	h.Del("Content-Encoding") // e.g. left by a precompressed file
+	wctx.Response().WriteHeader(gerr.Status())
+
+	if wctx.Request().Method == http.MethodHead {
+		return
+	}
+
+	if _, err := wctx.Response().Write(b); err != nil {
+		wctx.Logger().Errorf("Error writing response: %v", err)
+	}
+}
+
+// renderNegotiated renders res in the most preferred acceptable format,
+// skipping formats whose marshaler cannot encode the value. It answers 406
+// when the Accept header rules out every registered format.
+func renderNegotiated(wctx *WebContext, res any) {
+	resp := wctx.Response()
+	addVary(resp.Header(), "Accept")
+
+	formats := negotiateFormats(wctx.Request().Header.Values("Accept"))
+	if len(formats) == 0 {
+		resp.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+
+	switch res.(type) {
+	case []byte, string, error:
+		Render(wctx, formats[0], res)
+		return
+	}
+
+	if wctx.Request().Method == http.MethodHead {
+		Render(wctx, formats[0], res)
+		return
+	}
+
+	for _, format := range formats {
+		marshal, _ := lookupMarshaler(format)
+
+		b, err := marshal.Handler(res)
+		if errors.Is(err, ErrorInvalidType) {
+			continue // e.g. text/html for a struct
+		}
+		if err != nil {
+			resp.WriteHeader(http.StatusInternalServerError)
+			wctx.Logger().Errorf("Marshaling error: %v", err)
+			return
+		}
+
+		Render(wctx, format, b)
+		return
+	}
+
+	resp.WriteHeader(http.StatusNotAcceptable)
+}
+
+// acceptRange is a parsed media range from an Accept header.
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// negotiateFormats returns the registered formats acceptable for the given
+// Accept header values, best first. No Accept header accepts everything.
+// Each format takes the quality of the most specific range matching its
+// content type; ties keep the server preference order.
+func negotiateFormats(accept []string) []FormatOption {
+	ranges := parseAccept(accept)
+
+	marshalersMu.RLock()
+	defer marshalersMu.RUnlock()
+
+	type candidate struct {
+		format FormatOption
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, format := range marshalerOrder {
+		ct := marshalers[format].ContentType
+		if ct == "" {
+			continue // raw data has no content type to negotiate
+		}
+
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if ranges != nil {
+			q = acceptQuality(ranges, mt)
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{format, q})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	ret := make([]FormatOption, len(candidates))
+	for i, c := range candidates {
+		ret[i] = c.format
+	}
+	return ret
+}
+
+// acceptQuality returns the quality of the most specific range matching
+// the media type mt, or 0.
+func acceptQuality(ranges []acceptRange, mt string) float64 {
+	typ, subtype, _ := strings.Cut(mt, "/")
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		}
+
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// parseAccept parses Accept header values; nil means no preference.
+func parseAccept(values []string) []acceptRange {
+	var ranges []acceptRange
+	for _, v := range values {
+		for part := range strings.SplitSeq(v, ",") {
+			mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+
+			typ, subtype, ok := strings.Cut(mt, "/")
+			if !ok {
+				continue
+			}
+
+			q := 1.0
+			if qs, ok := params["q"]; ok {
+				if f, err := strconv.ParseFloat(qs, 64); err == nil && f >= 0 && f <= 1 {
+					q = f
+				}
+			}
+
+			ranges = append(ranges, acceptRange{typ: typ, subtype: subtype, q: q})
+		}
+	}
+
+	if ranges == nil && len(values) > 0 {
+		return []acceptRange{} // header present but unusable: nothing matches
+	}
+	return ranges
+}
+
+// addVary adds a token to the Vary header unless it is already listed.
+func addVary(h http.Header, token string) {
+	for _, v := range h.Values("Vary") {
+		for part := range strings.SplitSeq(v, ",") {
+			if p := strings.TrimSpace(part); p == "*" || strings.EqualFold(p, token) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", token)
+}
+
 // MarshalContent marshals data into a []byte, supporting common Go buffer types.
 func marshalContent(v any) ([]byte, error) {
 	switch r := v.(type) {
@@ -164,3 +164,92 @@ func Benchmark_marshalContent(b *testing.B) {
 		}
 	})
 }
+
+func TestRenderNegotiated(t *testing.T) {
+	type payload struct {
+		Key string `json:"key" xml:"key"`
+	}
+
+	tests := []struct {
+		name        string
+		accept      []string
+		data        any
+		status      int
+		contentType string
+		body        string
+	}{
+		{name: "no accept header", data: payload{"v"}, status: http.StatusOK, contentType: "application/json", body: `{"key":"v"}`},
+		{name: "wildcard", accept: []string{"*/*"}, data: payload{"v"}, status: http.StatusOK, contentType: "application/json", body: `{"key":"v"}`},
+		{name: "exact", accept: []string{"application/xml"}, data: payload{"v"}, status: http.StatusOK, contentType: "application/xml", body: `<payload><key>v</key></payload>`},
+		{name: "q values", accept: []string{"application/json;q=0.5, application/xml;q=0.9"}, data: payload{"v"}, status: http.StatusOK, contentType: "application/xml", body: `<payload><key>v</key></payload>`},
+		{name: "multiple headers", accept: []string{"application/json;q=0.1", "application/xml"}, data: payload{"v"}, status: http.StatusOK, contentType: "application/xml", body: `<payload><key>v</key></payload>`},
+		{name: "specific range wins", accept: []string{"application/*;q=0.2, application/json;q=0"}, data: payload{"v"}, status: http.StatusOK, contentType: "application/xml", body: `<payload><key>v</key></payload>`},
+		{name: "skips formats that cannot encode", accept: []string{"text/html, application/json;q=0.1"}, data: payload{"v"}, status: http.StatusOK, contentType: "application/json", body: `{"key":"v"}`},
+		{name: "strings use the best format", accept: []string{"text/html"}, data: "<b>hi</b>", status: http.StatusOK, contentType: "text/html; charset=UTF-8", body: "<b>hi</b>"},
+		{name: "plain text", accept: []string{"text/plain"}, data: "hello", status: http.StatusOK, contentType: "text/plain; charset=utf-8", body: "hello"},
+		{name: "nothing acceptable", accept: []string{"image/png"}, data: payload{"v"}, status: http.StatusNotAcceptable},
+		{name: "only encodable format refused", accept: []string{"text/html"}, data: payload{"v"}, status: http.StatusNotAcceptable},
+		{name: "garbage header", accept: []string{"nonsense"}, data: payload{"v"}, status: http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, v := range tt.accept {
+				req.Header.Add("Accept", v)
+			}
+			resp := httptest.NewRecorder()
+
+			NewTestWebContext(req, resp).RenderNegotiated(tt.data)
+
+			assert.Equal(t, tt.status, resp.Code)
+			assert.Equal(t, "Accept", resp.Header().Get("Vary"))
+			if tt.status == http.StatusOK {
+				assert.Equal(t, tt.contentType, resp.Header().Get("Content-Type"))
+				assert.Equal(t, tt.body, resp.Body.String())
+			}
+		})
+	}
+
+	t.Run("it should not duplicate Vary", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		resp := httptest.NewRecorder()
+		resp.Header().Set("Vary", "Origin, accept")
+
+		NewTestWebContext(req, resp).RenderNegotiated(payload{"v"})
+		assert.Equal(t, []string{"Origin, accept"}, resp.Header().Values("Vary"))
+	})
+}
+
+func TestRegisterMarshalerConcurrent(t *testing.T) {
+	const formatYAML FormatOption = 0x0100
+	t.Cleanup(func() {
+		marshalersMu.Lock()
+		delete(marshalers, formatYAML)
+		marshalerOrder = marshalerOrder[:len(marshalerOrder)-1]
+		marshalersMu.Unlock()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			RegisterMarshaler(formatYAML, func(any) ([]byte, error) { return []byte("key: v"), nil }, "application/yaml")
+		}
+	}()
+
+	for range 100 {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "application/yaml, application/json;q=0.5")
+		NewTestWebContext(req, httptest.NewRecorder()).RenderNegotiated(map[string]string{"key": "v"})
+	}
+	<-done
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/yaml, application/json;q=0.5")
+	resp := httptest.NewRecorder()
+	NewTestWebContext(req, resp).RenderNegotiated(map[string]string{"key": "v"})
+
+	assert.Equal(t, "application/yaml", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "key: v", resp.Body.String())
+}
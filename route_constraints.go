@@ -0,0 +1,252 @@
+package golly
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+var (
+	ErrRouteVarNotFound = errors.New("route variable not found")
+	ErrInvalidUUID      = errors.New("invalid uuid")
+)
+
+// RouteConstraint reports whether a single path segment satisfies a named
+// constraint such as {id:int}. Constraints run on the request hot path, so
+// they should be allocation free.
+type RouteConstraint func(string) bool
+
+var (
+	routeConstraintsMu sync.RWMutex
+	routeConstraints   = map[string]RouteConstraint{
+		"int":      isIntSegment,
+		"uint":     isUintSegment,
+		"uuid":     isUUIDSegment,
+		"alpha":    isAlphaSegment,
+		"alphanum": isAlphaNumSegment,
+		"slug":     isSlugSegment,
+	}
+)
+
+// RegisterRouteConstraint registers a named constraint usable as {var:name}.
+// Call it at boot before the routes using it are registered; a name that
+// collides with an existing constraint replaces it.
+//
+// Example:
+//
+//	golly.RegisterRouteConstraint("hex", func(s string) bool {
+//	    _, err := hex.DecodeString(s)
+//	    return err == nil
+//	})
+//	app.Routes().Get("/colors/{code:hex}", handler)
+func RegisterRouteConstraint(name string, fn RouteConstraint) {
+	routeConstraintsMu.Lock()
+	defer routeConstraintsMu.Unlock()
+
+	routeConstraints[name] = fn
+}
+
+// lookupRouteConstraint returns the named constraint, if one is registered.
+func lookupRouteConstraint(name string) (RouteConstraint, bool) {
+	routeConstraintsMu.RLock()
+	defer routeConstraintsMu.RUnlock()
+
+	fn, ok := routeConstraints[name]
+	return fn, ok
+}
+
+// GetInt returns the variable parsed as an int.
+func (rv *RouteVars) GetInt(key string) (int, error) {
+	str, ok := rv.lookup(key)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrRouteVarNotFound, key)
+	}
+
+	v, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("route variable %s: %w", key, err)
+	}
+	return v, nil
+}
+
+// GetInt64 returns the variable parsed as an int64.
+func (rv *RouteVars) GetInt64(key string) (int64, error) {
+	str, ok := rv.lookup(key)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrRouteVarNotFound, key)
+	}
+
+	v, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("route variable %s: %w", key, err)
+	}
+	return v, nil
+}
+
+// GetUUID returns the variable parsed as a UUID.
+func (rv *RouteVars) GetUUID(key string) (UUID, error) {
+	str, ok := rv.lookup(key)
+	if !ok {
+		return UUID{}, fmt.Errorf("%w: %s", ErrRouteVarNotFound, key)
+	}
+
+	v, err := ParseUUID(str)
+	if err != nil {
+		return UUID{}, fmt.Errorf("route variable %s: %w", key, err)
+	}
+	return v, nil
+}
+
+// UUID is a 16 byte RFC 4122 identifier in its binary form.
+type UUID [16]byte
+
+// ParseUUID parses the canonical 8-4-4-4-12 hex form of a UUID.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+
+	if !isUUIDSegment(s) {
+		return u, ErrInvalidUUID
+	}
+
+	// Group boundaries within the 36 character form
+	groups := [5][2]int{{0, 8}, {9, 13}, {14, 18}, {19, 23}, {24, 36}}
+
+	pos := 0
+	for _, g := range groups {
+		n, err := hex.Decode(u[pos:], unsafeBytes(s[g[0]:g[1]]))
+		if err != nil {
+			return UUID{}, ErrInvalidUUID
+		}
+		pos += n
+	}
+
+	return u, nil
+}
+
+// String returns the canonical lowercase 8-4-4-4-12 form.
+func (u UUID) String() string {
+	var buf [36]byte
+
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+
+	return string(buf[:])
+}
+
+// MarshalText encodes the UUID in its canonical string form.
+func (u UUID) MarshalText() ([]byte, error) { return []byte(u.String()), nil }
+
+// UnmarshalText parses the canonical string form, so UUIDs bind from path,
+// query and JSON values.
+func (u *UUID) UnmarshalText(b []byte) error {
+	v, err := ParseUUID(string(b))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// ***************************************************************************
+// *  Built-in constraints
+// ***************************************************************************
+
+func isUintSegment(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isIntSegment(s string) bool {
+	if len(s) > 1 && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	return isUintSegment(s)
+}
+
+func isUUIDSegment(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHexByte(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isAlphaSegment(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !isAlphaByte(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphaNumSegment(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !isAlphaByte(s[i]) && !isDigitByte(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSlugSegment accepts lowercase words joined by single hyphens, e.g. "hello-world-2".
+func isSlugSegment(s string) bool {
+	if s == "" || s[0] == '-' || s[len(s)-1] == '-' {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', isDigitByte(c):
+		case c == '-':
+			if s[i-1] == '-' {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isDigitByte(c byte) bool { return c >= '0' && c <= '9' }
+func isAlphaByte(c byte) bool { return (c|0x20) >= 'a' && (c|0x20) <= 'z' }
+func isHexByte(c byte) bool   { return isDigitByte(c) || ((c|0x20) >= 'a' && (c|0x20) <= 'f') }
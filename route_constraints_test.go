@@ -0,0 +1,145 @@
+package golly
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltinRouteConstraints(t *testing.T) {
+	tests := []struct {
+		constraint string
+		valid      []string
+		invalid    []string
+	}{
+		{
+			constraint: "int",
+			valid:      []string{"0", "42", "-7", "+7"},
+			invalid:    []string{"", "-", "4a", "1.5"},
+		},
+		{
+			constraint: "uint",
+			valid:      []string{"0", "42"},
+			invalid:    []string{"", "-7", "4a"},
+		},
+		{
+			constraint: "uuid",
+			valid:      []string{"019bd7b0-15ee-71e4-ae51-7523f6b9bb02", "019BD7B0-15EE-71E4-AE51-7523F6B9BB02"},
+			invalid:    []string{"", "019bd7b015ee71e4ae517523f6b9bb02", "019bd7b0-15ee-71e4-ae51-7523f6b9bbzz"},
+		},
+		{
+			constraint: "alpha",
+			valid:      []string{"abc", "ABC"},
+			invalid:    []string{"", "abc1", "a-b"},
+		},
+		{
+			constraint: "alphanum",
+			valid:      []string{"abc1", "A1"},
+			invalid:    []string{"", "a-b", "a_b"},
+		},
+		{
+			constraint: "slug",
+			valid:      []string{"hello", "hello-world-2"},
+			invalid:    []string{"", "-hello", "hello-", "hello--world", "Hello"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			fn, ok := lookupRouteConstraint(tt.constraint)
+			if !assert.True(t, ok) {
+				return
+			}
+
+			for _, s := range tt.valid {
+				assert.True(t, fn(s), "%s should match %q", tt.constraint, s)
+			}
+			for _, s := range tt.invalid {
+				assert.False(t, fn(s), "%s should not match %q", tt.constraint, s)
+			}
+		})
+	}
+}
+
+func TestRouteConstraintRouting(t *testing.T) {
+	RegisterRouteConstraint("even", func(s string) bool {
+		n, err := strconv.Atoi(s)
+		return err == nil && n%2 == 0
+	})
+
+	root := NewRouteRoot()
+	root.Get("/users/{id:int}", noOpHandler).
+		Get("/users/{slug:slug}", noOpHandler).
+		Get("/numbers/{n:even}", noOpHandler)
+
+	t.Run("it should route by constraint", func(t *testing.T) {
+		r := FindRoute(root, "/users/42")
+		if assert.NotNil(t, r) {
+			assert.Equal(t, "id", r.token.value)
+			assert.NotNil(t, r.token.constraint)
+			assert.Nil(t, r.token.re)
+		}
+
+		r = FindRoute(root, "/users/jane-doe")
+		if assert.NotNil(t, r) {
+			assert.Equal(t, "slug", r.token.value)
+		}
+
+		assert.Nil(t, FindRoute(root, "/users/Jane_Doe"))
+	})
+
+	t.Run("it should use application registered constraints", func(t *testing.T) {
+		assert.NotNil(t, FindRoute(root, "/numbers/4"))
+		assert.Nil(t, FindRoute(root, "/numbers/3"))
+	})
+}
+
+func TestRouteVarsTypedAccessors(t *testing.T) {
+	rv := &RouteVars{}
+	rv.set("id", "42")
+	rv.set("bad", "4x")
+	rv.set("uuid", "019bd7b0-15ee-71e4-ae51-7523f6b9bb02")
+
+	t.Run("GetInt", func(t *testing.T) {
+		v, err := rv.GetInt("id")
+		assert.NoError(t, err)
+		assert.Equal(t, 42, v)
+
+		_, err = rv.GetInt("bad")
+		assert.ErrorIs(t, err, strconv.ErrSyntax)
+
+		_, err = rv.GetInt("missing")
+		assert.ErrorIs(t, err, ErrRouteVarNotFound)
+	})
+
+	t.Run("GetInt64", func(t *testing.T) {
+		v, err := rv.GetInt64("id")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), v)
+	})
+
+	t.Run("GetUUID", func(t *testing.T) {
+		v, err := rv.GetUUID("uuid")
+		assert.NoError(t, err)
+		assert.Equal(t, "019bd7b0-15ee-71e4-ae51-7523f6b9bb02", v.String())
+
+		_, err = rv.GetUUID("id")
+		assert.True(t, errors.Is(err, ErrInvalidUUID))
+	})
+}
+
+func BenchmarkRouteConstraint(b *testing.B) {
+	root := NewRouteRoot()
+	root.Get("/users/{id:uuid}/posts/{n:int}", noOpHandler)
+
+	segments := []string{"/", "users", "019bd7b0-15ee-71e4-ae51-7523f6b9bb02", "posts", "42"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		FindRouteBySegments(root, segments)
+	}
+}
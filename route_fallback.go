@@ -0,0 +1,127 @@
+package golly
+
+import "net/http"
+
+// NotFound registers the handler used for unmatched paths under this
+// namespace. It runs through the middleware chain of the deepest route that
+// matched a prefix of the request path, so request logging, CORS and error
+// rendering behave as they do for real routes. The handler is responsible
+// for writing the status code.
+//
+// Fallbacks belong to the namespace, not to a Group: unmatched paths are
+// not registered through any group, so calling NotFound on a group (or on
+// a namespace opened inside one) panics. Register it outside the group.
+//
+// Example:
+//
+//	app.Routes().Namespace("/api", func(r *golly.Route) {
+//	    r.Use(middleware.RequestLogger)
+//	    r.NotFound(func(wctx *golly.WebContext) {
+//	        wctx.WithStatus(http.StatusNotFound).RenderJSON(
+//	            golly.NewError(http.StatusNotFound, nil))
+//	    })
+//	})
+func (re *Route) NotFound(h HandlerFunc) *Route {
+	re.mustNotBeView("NotFound")
+
+	n := re.node()
+	n.notFoundHandler = h
+	n.appRoot().fallbacks = true
+	n.updateHandlers()
+
+	return re
+}
+
+// MethodNotAllowed registers the handler used when a route under this
+// namespace exists but does not accept the request method. The Allow header
+// is set before the handler runs; the handler writes the status code. Like
+// NotFound it cannot be called on a group.
+func (re *Route) MethodNotAllowed(h HandlerFunc) *Route {
+	re.mustNotBeView("MethodNotAllowed")
+
+	n := re.node()
+	n.methodNotAllowedHandler = h
+	n.updateHandlers()
+
+	return re
+}
+
+// mustNotBeView panics when re is a group view: fallbacks are stored on the
+// tree node, where they would leak to siblings outside the group.
+func (re *Route) mustNotBeView(method string) {
+	if re.target != nil {
+		panic("golly: " + method + " cannot be registered inside a Group; register it on the enclosing namespace")
+	}
+}
+
+// ancestor returns the parent route, crossing from a host tree into the
+// root it is mounted on.
+func (re *Route) ancestor() *Route {
+	if re.parent == nil && re.hostOf != nil {
+		return re.hostOf
+	}
+	return re.parent
+}
+
+// appRoot returns the application root this route ultimately hangs off.
+func (re *Route) appRoot() *Route {
+	root := re.node()
+	for p := root.ancestor(); p != nil; p = p.ancestor() {
+		root = p
+	}
+	return root
+}
+
+// resolveFallback returns the first handler picked by get, starting at this
+// route and walking up through its ancestors.
+func (re *Route) resolveFallback(get func(*Route) HandlerFunc) HandlerFunc {
+	for cur := re; cur != nil; cur = cur.ancestor() {
+		if h := get(cur); h != nil {
+			return h
+		}
+	}
+	return nil
+}
+
+// closestRoute returns the deepest route matching a prefix of segments.
+// Only used on the miss path, never allocates.
+func closestRoute(root *Route, segments []string) *Route {
+	if len(segments) == 0 || root.token == nil || !root.token.Match(&segments[0]) {
+		return root
+	}
+
+	best, _ := root.closest(segments, 1)
+	return best
+}
+
+func (re *Route) closest(segments []string, depth int) (*Route, int) {
+	best, bestDepth := re, depth
+	if len(segments) < 2 {
+		return best, bestDepth
+	}
+
+	rest := segments[1:]
+	for _, child := range re.children {
+		if child.token.isCatchAll || !child.token.Match(&rest[0]) {
+			continue
+		}
+
+		if r, d := child.closest(rest, depth+1); d > bestDepth {
+			best, bestDepth = r, d
+		}
+	}
+
+	return best, bestDepth
+}
+
+// serveFallback runs a fallback handler with a pooled WebContext bound to route.
+func serveFallback(a *Application, r *http.Request, w http.ResponseWriter, route *Route, segments []string, h HandlerFunc) {
+	wctx := a.wctxPool.Get().(*WebContext)
+	wctx.Reset(r.Context(), r, w, segments)
+	defer a.wctxPool.Put(wctx)
+
+	wctx.route = route
+	wctx.loadVars()
+
+	h(wctx)
+}
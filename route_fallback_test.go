@@ -0,0 +1,156 @@
+package golly
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteFallbacks(t *testing.T) {
+	app := NewApplication(Options{})
+
+	tag := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(wctx *WebContext) {
+				wctx.ResponseHeaders().Add("X-Chain", name)
+				next(wctx)
+			}
+		}
+	}
+
+	app.routes.
+		Use(tag("root")).
+		NotFound(func(wctx *WebContext) {
+			wctx.WithStatus(http.StatusNotFound).RenderText("root not found")
+		}).
+		Get("/users", noOpHandler).
+		Namespace("/api", func(r *Route) {
+			r.Use(tag("api"))
+			r.NotFound(func(wctx *WebContext) {
+				wctx.WithStatus(http.StatusNotFound).RenderJSON(map[string]string{"error": "not found"})
+			})
+			r.MethodNotAllowed(func(wctx *WebContext) {
+				wctx.WithStatus(http.StatusMethodNotAllowed).RenderJSON(map[string]string{"error": "method"})
+			})
+
+			r.Namespace("/orgs/{org}", func(r *Route) {
+				r.Use(tag("orgs"))
+				r.Get("/members", func(wctx *WebContext) {
+					wctx.RenderText("members")
+				})
+			})
+		})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		body   string
+		chain  []string
+		allow  string
+	}{
+		{
+			name:   "root not found",
+			method: http.MethodGet,
+			path:   "/missing",
+			status: http.StatusNotFound,
+			body:   "root not found",
+			chain:  []string{"root"},
+		},
+		{
+			name:   "namespace not found",
+			method: http.MethodGet,
+			path:   "/api/missing",
+			status: http.StatusNotFound,
+			body:   `{"error":"not found"}`,
+			chain:  []string{"root", "api"},
+		},
+		{
+			name:   "closest ancestor middleware",
+			method: http.MethodGet,
+			path:   "/api/orgs/acme/missing",
+			status: http.StatusNotFound,
+			body:   `{"error":"not found"}`,
+			chain:  []string{"root", "api", "orgs"},
+		},
+		{
+			name:   "method not allowed",
+			method: http.MethodPost,
+			path:   "/api/orgs/acme/members",
+			status: http.StatusMethodNotAllowed,
+			body:   `{"error":"method"}`,
+			chain:  []string{"root", "api", "orgs"},
+			allow:  "GET",
+		},
+		{
+			name:   "default method not allowed outside namespace",
+			method: http.MethodPost,
+			path:   "/users",
+			status: http.StatusMethodNotAllowed,
+			allow:  "GET",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			RouteRequest(app, req, w)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.body, string(body))
+			assert.Equal(t, tt.chain, resp.Header.Values("X-Chain"))
+			assert.Equal(t, tt.allow, resp.Header.Get(HeaderAllow))
+		})
+	}
+
+	t.Run("it should expose route vars of the closest match", func(t *testing.T) {
+		var org string
+
+		app := NewApplication(Options{})
+		app.routes.Namespace("/orgs/{org}", func(r *Route) {
+			r.Get("/members", noOpHandler)
+			r.NotFound(func(wctx *WebContext) {
+				org = wctx.URLParams().Get("org")
+				wctx.WithStatus(http.StatusNotFound)
+			})
+		})
+
+		w := httptest.NewRecorder()
+		RouteRequest(app, httptest.NewRequest(http.MethodGet, "/orgs/acme/nope/deeper", nil), w)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "acme", org)
+	})
+
+	t.Run("it should reject fallbacks inside a group", func(t *testing.T) {
+		app := NewApplication(Options{})
+		fallback := func(wctx *WebContext) {}
+
+		assert.Panics(t, func() {
+			app.routes.Group(func(r *Route) { r.NotFound(fallback) })
+		})
+		assert.Panics(t, func() {
+			app.routes.Group(func(r *Route) { r.MethodNotAllowed(fallback) })
+		})
+		assert.Panics(t, func() {
+			app.routes.Group(func(r *Route) {
+				r.Namespace("/admin", func(r *Route) { r.NotFound(fallback) })
+			})
+		})
+
+		// Siblings outside the group keep the plain 404
+		w := httptest.NewRecorder()
+		RouteRequest(app, httptest.NewRequest(http.MethodGet, "/missing", nil), w)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Nil(t, app.routes.notFoundHandler)
+	})
+}
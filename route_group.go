@@ -0,0 +1,81 @@
+package golly
+
+// Group opens an anonymous group at this route's path. Routes registered
+// through the group share the prefix with their siblings but get the group's
+// middleware on top of the tree middleware, without leaking it to routes
+// registered outside the group. Groups nest, and namespaces opened inside a
+// group stay inside it.
+//
+// Example:
+//
+//	app.Routes().Namespace("/api", func(r *golly.Route) {
+//	    r.Get("/status", status)
+//
+//	    r.Group(func(r *golly.Route) {
+//	        r.Use(auth.Required)
+//	        r.Get("/me", me)
+//	        r.Namespace("/orders", func(r *golly.Route) {
+//	            r.Get("/", listOrders)
+//	        })
+//	    })
+//	})
+func (re *Route) Group(f func(r *Route)) *Route {
+	f(re.view(re.node()))
+
+	return re
+}
+
+// view returns a group view registering into node, nested under re when
+// re is itself a group view.
+func (re *Route) view(node *Route) *Route {
+	root := node.root
+	if root == nil {
+		root = node
+	}
+
+	g := &Route{
+		target:     node,
+		root:       root,
+		middleware: []MiddlewareFunc{},
+	}
+
+	if re.target != nil {
+		g.outer = re
+	}
+
+	return g
+}
+
+// node returns the tree node a route operates on: itself, or the target of
+// a group view.
+func (re *Route) node() *Route {
+	if re.target != nil {
+		return re.target
+	}
+	return re
+}
+
+// slotMiddleware returns the middleware for handler slot idx: the tree
+// middleware followed by the stack of the group it was registered through.
+func (re *Route) slotMiddleware(idx int, middleware []MiddlewareFunc) []MiddlewareFunc {
+	g := re.groups[idx]
+	if g == nil {
+		return middleware
+	}
+
+	n := len(middleware)
+	for cur := g; cur != nil; cur = cur.outer {
+		n += len(cur.middleware)
+	}
+
+	mw := make([]MiddlewareFunc, n)
+	copy(mw, middleware)
+
+	// Outermost group first, innermost last
+	for cur := g; cur != nil; cur = cur.outer {
+		n -= len(cur.middleware)
+		copy(mw[n:], cur.middleware)
+	}
+
+	return mw
+}
@@ -0,0 +1,149 @@
+package golly
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func chainTag(name string) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(wctx *WebContext) {
+			wctx.ResponseHeaders().Add("X-Chain", name)
+			next(wctx)
+		}
+	}
+}
+
+func serveChain(app *Application, method, path string) []string {
+	return serveRequest(app, httptest.NewRequest(method, path, nil)).Header().Values("X-Chain")
+}
+
+func TestRouteGroups(t *testing.T) {
+	app := NewApplication(Options{})
+
+	app.routes.Use(chainTag("root"))
+	app.routes.Namespace("/api", func(r *Route) {
+		r.Use(chainTag("api"))
+		r.Get("/status", noOpHandler)
+
+		r.Group(func(r *Route) {
+			r.Use(chainTag("auth"))
+			r.Get("/me", noOpHandler)
+
+			r.Group(func(r *Route) {
+				r.Use(chainTag("admin"))
+				r.Delete("/me", noOpHandler)
+			})
+
+			r.Namespace("/orders", func(r *Route) {
+				r.Use(chainTag("orders"))
+				r.Get("/{id}", noOpHandler)
+			})
+		})
+
+		r.Group(func(r *Route) {
+			r.Use(chainTag("public"))
+			r.Get("/docs", noOpHandler)
+			r.Post("/me", noOpHandler)
+		})
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		expected []string
+	}{
+		{name: "outside any group", method: http.MethodGet, path: "/api/status", expected: []string{"root", "api"}},
+		{name: "group", method: http.MethodGet, path: "/api/me", expected: []string{"root", "api", "auth"}},
+		{name: "nested group", method: http.MethodDelete, path: "/api/me", expected: []string{"root", "api", "auth", "admin"}},
+		{name: "sibling group same node", method: http.MethodPost, path: "/api/me", expected: []string{"root", "api", "public"}},
+		{name: "sibling group", method: http.MethodGet, path: "/api/docs", expected: []string{"root", "api", "public"}},
+		{name: "namespace inside group", method: http.MethodGet, path: "/api/orders/1", expected: []string{"root", "api", "auth", "orders"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, serveChain(app, tt.method, tt.path))
+		})
+	}
+
+	t.Run("it should keep group middleware off the tree", func(t *testing.T) {
+		api := FindRoute(app.routes, "/api")
+		assert.Len(t, api.middleware, 1)
+	})
+
+	t.Run("it should answer preflights through the group stack", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/api/me", nil)
+		req.Header.Set(HeaderAccessControlRequestMethod, http.MethodDelete)
+
+		w := httptest.NewRecorder()
+		RouteRequest(app, req, w)
+
+		assert.Equal(t, []string{"root", "api", "auth", "admin"}, w.Header().Values("X-Chain"))
+	})
+}
+
+func TestUpdateHandlersLateMiddleware(t *testing.T) {
+	t.Run("it should rechain when tree middleware is added after routes", func(t *testing.T) {
+		app := NewApplication(Options{})
+		app.routes.Namespace("/api", func(r *Route) {
+			r.Get("/a", noOpHandler)
+			r.Group(func(g *Route) {
+				g.Get("/b", noOpHandler)
+			})
+		})
+
+		assert.Empty(t, serveChain(app, http.MethodGet, "/api/a"))
+
+		app.routes.Use(chainTag("root"))
+		FindRoute(app.routes, "/api").Use(chainTag("api"))
+
+		assert.Equal(t, []string{"root", "api"}, serveChain(app, http.MethodGet, "/api/a"))
+		assert.Equal(t, []string{"root", "api"}, serveChain(app, http.MethodGet, "/api/b"))
+	})
+
+	t.Run("it should rechain when group middleware is added after routes", func(t *testing.T) {
+		app := NewApplication(Options{})
+
+		var group *Route
+		app.routes.Group(func(g *Route) {
+			group = g
+			g.Get("/a", noOpHandler)
+			g.Namespace("/n", func(r *Route) {
+				r.Get("/b", noOpHandler)
+			})
+		})
+		app.routes.Get("/c", noOpHandler)
+
+		group.Use(chainTag("late"))
+
+		assert.Equal(t, []string{"late"}, serveChain(app, http.MethodGet, "/a"))
+		assert.Equal(t, []string{"late"}, serveChain(app, http.MethodGet, "/n/b"))
+		assert.Empty(t, serveChain(app, http.MethodGet, "/c"))
+	})
+
+	t.Run("it should rechain siblings and fallbacks independently", func(t *testing.T) {
+		app := NewApplication(Options{})
+
+		app.routes.Use(chainTag("a"), chainTag("b"), chainTag("c"))
+
+		app.routes.Namespace("/x", func(r *Route) {
+			r.Use(chainTag("x"))
+			r.Get("/", noOpHandler)
+			r.NotFound(func(wctx *WebContext) { wctx.WithStatus(http.StatusNotFound) })
+		})
+		app.routes.Namespace("/y", func(r *Route) {
+			r.Use(chainTag("y"))
+			r.Get("/", noOpHandler)
+		})
+
+		app.routes.Use(chainTag("d"))
+
+		assert.Equal(t, []string{"a", "b", "c", "d", "x"}, serveChain(app, http.MethodGet, "/x/missing"))
+		assert.Equal(t, []string{"a", "b", "c", "d", "y"}, serveChain(app, http.MethodGet, "/y"))
+	})
+}
@@ -0,0 +1,173 @@
+package golly
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// hostRoute is a route tree mounted under a host pattern such as
+// "api.example.com" or "{tenant}.example.com".
+type hostRoute struct {
+	pattern string
+	labels  []RouteToken // one token per dot separated label
+	tree    *Route
+}
+
+// Host mounts a route subtree that only serves requests whose Host header
+// matches pattern. Labels wrapped in braces capture into RouteVars
+// ({tenant}.example.com) and accept matchers like path tokens
+// ({tenant:alpha}.example.com). Static labels compare case-insensitively.
+//
+// Host trees hang off the root and inherit the root's middleware. Requests
+// whose host matches no pattern are served by the default tree.
+//
+// Example:
+//
+//	app.Routes().
+//	    Host("admin.example.com", func(r *golly.Route) {
+//	        r.Get("/", adminIndex)
+//	    }).
+//	    Host("{tenant}.example.com", func(r *golly.Route) {
+//	        r.Get("/", func(wctx *golly.WebContext) {
+//	            wctx.RenderText(wctx.URLParams().Get("tenant"))
+//	        })
+//	    })
+func (re *Route) Host(pattern string, f func(r *Route)) *Route {
+	root := re.root
+	if root == nil {
+		root = re
+	}
+
+	hr := root.findHost(pattern)
+	if hr == nil {
+		hr = newHostRoute(root, pattern)
+		root.hosts = append(root.hosts, hr)
+
+		// Fully static hosts win over patterns; otherwise keep registration order
+		sort.SliceStable(root.hosts, func(i, j int) bool {
+			return !root.hosts[i].isDynamic() && root.hosts[j].isDynamic()
+		})
+	}
+
+	f(hr.tree)
+
+	return re
+}
+
+func (re *Route) findHost(pattern string) *hostRoute {
+	for _, hr := range re.hosts {
+		if hr.pattern == pattern {
+			return hr
+		}
+	}
+	return nil
+}
+
+// matchHost returns the host tree serving host, or nil for the default tree.
+func (re *Route) matchHost(host string) *hostRoute {
+	if len(re.hosts) == 0 {
+		return nil
+	}
+
+	host = stripHostPort(host)
+	for _, hr := range re.hosts {
+		if hr.match(host, nil) {
+			return hr
+		}
+	}
+	return nil
+}
+
+func newHostRoute(root *Route, pattern string) *hostRoute {
+	hr := &hostRoute{pattern: pattern}
+
+	for _, label := range strings.Split(pattern, ".") {
+		tok := RouteToken{value: label}
+
+		if n := len(label); n > 2 && label[0] == '{' && label[n-1] == '}' {
+			name, matcher, _ := strings.Cut(label[1:n-1], ":")
+			tok = RouteToken{value: name, matcher: matcher, isDynamic: true}
+
+			if err := tok.compile(); err != nil {
+				panic(fmt.Sprintf("golly: host %q: %v", pattern, err))
+			}
+		}
+
+		hr.labels = append(hr.labels, tok)
+	}
+
+	hr.tree = NewRouteRoot()
+	hr.tree.host = hr
+	hr.tree.hostOf = root
+	hr.tree.updateHandlers()
+
+	return hr
+}
+
+func (hr *hostRoute) isDynamic() bool {
+	for pos := range hr.labels {
+		if hr.labels[pos].isDynamic {
+			return true
+		}
+	}
+	return false
+}
+
+// match walks host label by label without allocating. When rv is non-nil,
+// captured labels are stored as string views into host.
+func (hr *hostRoute) match(host string, rv *RouteVars) bool {
+	pos := 0
+	for i := range hr.labels {
+		if pos > len(host) {
+			return false
+		}
+
+		end := strings.IndexByte(host[pos:], '.')
+		last := i == len(hr.labels)-1
+
+		var label string
+		switch {
+		case last && end >= 0:
+			return false // host has more labels than the pattern
+		case !last && end < 0:
+			return false // host has fewer labels than the pattern
+		case end < 0:
+			label = host[pos:]
+		default:
+			label = host[pos : pos+end]
+		}
+
+		tok := &hr.labels[i]
+		if tok.isDynamic {
+			if label == "" || !tok.Match(&label) {
+				return false
+			}
+			if rv != nil {
+				rv.set(tok.value, label)
+			}
+		} else if !ASCIICompair(tok.value, label) {
+			return false
+		}
+
+		pos += len(label) + 1
+	}
+
+	return true
+}
+
+// stripHostPort removes a trailing :port, leaving IPv6 literals intact.
+func stripHostPort(host string) string {
+	for i := len(host) - 1; i >= 0; i-- {
+		switch host[i] {
+		case ':':
+			return host[:i]
+		case ']':
+			return host
+		case '.':
+			// Ports never contain dots; stop scanning early
+			return host
+		}
+	}
+	return host
+}
@@ -0,0 +1,95 @@
+package golly
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostRouting(t *testing.T) {
+	app := NewApplication(Options{})
+
+	write := func(body string) HandlerFunc {
+		return func(wctx *WebContext) {
+			wctx.RenderText(body)
+		}
+	}
+
+	app.routes.Use(func(next HandlerFunc) HandlerFunc {
+		return func(wctx *WebContext) {
+			wctx.ResponseHeaders().Set("X-Root", "1")
+			next(wctx)
+		}
+	})
+
+	app.routes.
+		Get("/", write("default")).
+		Host("{tenant}.example.com", func(r *Route) {
+			r.Get("/", func(wctx *WebContext) {
+				wctx.RenderText("tenant:" + wctx.URLParams().Get("tenant"))
+			})
+			r.Get("/users/{id}", func(wctx *WebContext) {
+				params := wctx.URLParams()
+				wctx.RenderText(params.Get("tenant") + "/" + params.Get("id"))
+			})
+		}).
+		Host("admin.example.com", func(r *Route) {
+			r.Get("/", write("admin"))
+		}).
+		Host("{region:alpha}.api.example.com", func(r *Route) {
+			r.Get("/", func(wctx *WebContext) {
+				wctx.RenderText("api:" + wctx.URLParams().Get("region"))
+			})
+		})
+
+	tests := []struct {
+		name   string
+		host   string
+		path   string
+		status int
+		body   string
+	}{
+		{name: "default tree", host: "example.com", path: "/", status: http.StatusOK, body: "default"},
+		{name: "static host wins over pattern", host: "admin.example.com", path: "/", status: http.StatusOK, body: "admin"},
+		{name: "static host is case-insensitive", host: "ADMIN.example.com:9000", path: "/", status: http.StatusOK, body: "admin"},
+		{name: "tenant capture", host: "acme.example.com", path: "/", status: http.StatusOK, body: "tenant:acme"},
+		{name: "tenant capture with path vars", host: "acme.example.com:8080", path: "/users/7", status: http.StatusOK, body: "acme/7"},
+		{name: "host matcher", host: "eu.api.example.com", path: "/", status: http.StatusOK, body: "api:eu"},
+		{name: "host matcher rejects", host: "eu1.api.example.com", path: "/", status: http.StatusOK, body: "default"},
+		{name: "path miss in host tree", host: "acme.example.com", path: "/missing", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Host = tt.host
+
+			w := httptest.NewRecorder()
+			RouteRequest(app, req, w)
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			if tt.body != "" {
+				assert.Equal(t, tt.body, string(body))
+				assert.Equal(t, "1", resp.Header.Get("X-Root"), "root middleware should apply")
+			}
+		})
+	}
+}
+
+func TestStripHostPort(t *testing.T) {
+	for in, expected := range map[string]string{
+		"example.com":      "example.com",
+		"example.com:9000": "example.com",
+		"localhost:80":     "localhost",
+		"[::1]:80":         "[::1]",
+		"[::1]":            "[::1]",
+	} {
+		assert.Equal(t, expected, stripHostPort(in), in)
+	}
+}
@@ -0,0 +1,69 @@
+package golly
+
+import "net/http"
+
+// AutoMethods toggles automatic HEAD and OPTIONS handling for the whole
+// router. When enabled, routes without an explicit HEAD handler answer HEAD
+// with their GET handler and the body discarded, and routes without an
+// explicit OPTIONS handler answer OPTIONS with 204 and the computed Allow
+// header. Both run through the route middleware, so a CORS middleware still
+// gets the first look at OPTIONS requests.
+func (re *Route) AutoMethods(enabled bool) *Route {
+	root := re.appRoot()
+	root.autoMethods = enabled
+	root.updateHandlers()
+
+	return re
+}
+
+// autoMethodHandlers fills the HEAD/OPTIONS slots derived from registered
+// handlers and returns the methods it implied.
+func (re *Route) autoMethodHandlers(middleware []MiddlewareFunc) methodType {
+	if re.allowed == 0 || !re.appRoot().autoMethods {
+		return 0
+	}
+
+	var implied methodType
+
+	getIdx, headIdx, optIdx := methodIndex(GET), methodIndex(HEAD), methodIndex(OPTIONS)
+
+	if get := re.chained[getIdx]; get != nil && re.chained[headIdx] == nil {
+		re.chained[headIdx] = discardBody(get)
+		implied |= HEAD
+	}
+
+	if re.chained[optIdx] == nil {
+		re.chained[optIdx] = chain(middleware, autoOptionsHandler)
+		implied |= OPTIONS
+	}
+
+	return implied
+}
+
+// discardBody runs h with the response body discarded, then forwards the
+// status the handler settled on so HEAD mirrors GET minus the payload.
+func discardBody(h HandlerFunc) HandlerFunc {
+	return func(wctx *WebContext) {
+		ww, ok := wctx.writer.(WrapResponseWriter)
+		if !ok {
+			h(wctx)
+			return
+		}
+
+		ww.Discard()
+		h(wctx)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		ww.Unwrap().WriteHeader(status)
+	}
+}
+
+func autoOptionsHandler(wctx *WebContext) {
+	if wctx.route != nil {
+		wctx.ResponseHeaders().Set(HeaderAllow, wctx.route.allowHeader)
+	}
+	wctx.writer.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,85 @@
+package golly
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoMethods(t *testing.T) {
+	setup := func(auto bool) *Application {
+		app := NewApplication(Options{})
+		app.routes.
+			Use(func(next HandlerFunc) HandlerFunc {
+				return func(wctx *WebContext) {
+					wctx.ResponseHeaders().Set("X-Middleware", "1")
+					next(wctx)
+				}
+			}).
+			Get("/users", func(wctx *WebContext) {
+				wctx.ResponseHeaders().Set("X-Total", "2")
+				wctx.writer.WriteHeader(http.StatusAccepted)
+				_, _ = wctx.Write([]byte("users"))
+			}).
+			Post("/users", noOpHandler).
+			Options("/custom", func(wctx *WebContext) {
+				wctx.writer.WriteHeader(http.StatusTeapot)
+			}).
+			Get("/custom", noOpHandler)
+
+		app.routes.AutoMethods(auto)
+		return app
+	}
+
+	t.Run("it should keep 405 when disabled", func(t *testing.T) {
+		app := setup(false)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, serveRequest(app, httptest.NewRequest(http.MethodHead, "/users", nil)).Code)
+		assert.Equal(t, http.StatusMethodNotAllowed, serveRequest(app, httptest.NewRequest(http.MethodOptions, "/users", nil)).Code)
+	})
+
+	t.Run("it should answer HEAD with the GET handler minus the body", func(t *testing.T) {
+		w := serveRequest(setup(true), httptest.NewRequest(http.MethodHead, "/users", nil))
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-Total"))
+		assert.Equal(t, "1", w.Header().Get("X-Middleware"))
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("it should answer OPTIONS with the Allow header", func(t *testing.T) {
+		w := serveRequest(setup(true), httptest.NewRequest(http.MethodOptions, "/users", nil))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "GET,HEAD,OPTIONS,POST", w.Header().Get(HeaderAllow))
+		assert.Equal(t, "1", w.Header().Get("X-Middleware"))
+	})
+
+	t.Run("it should prefer explicit handlers", func(t *testing.T) {
+		w := serveRequest(setup(true), httptest.NewRequest(http.MethodOptions, "/custom", nil))
+		assert.Equal(t, http.StatusTeapot, w.Code)
+	})
+
+	t.Run("it should include implied methods in 405 Allow", func(t *testing.T) {
+		w := serveRequest(setup(true), httptest.NewRequest(http.MethodPut, "/users", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET,HEAD,OPTIONS,POST", w.Header().Get(HeaderAllow))
+	})
+
+	t.Run("it should not list implied methods as registered", func(t *testing.T) {
+		app := setup(true)
+		re := FindRoute(app.routes, "/users")
+
+		assert.Equal(t, []string{"GET", "POST"}, re.Allow())
+	})
+
+	t.Run("it should apply to routes added after enabling", func(t *testing.T) {
+		app := setup(true)
+		app.routes.Get("/late", noOpHandler)
+
+		assert.Equal(t, http.StatusOK, serveRequest(app, httptest.NewRequest(http.MethodHead, "/late", nil)).Code)
+	})
+}
@@ -3,6 +3,7 @@ package golly
 import (
 	"reflect"
 	"strings"
+	"sync"
 )
 
 // ParamSource indicates where a parameter originates.
@@ -13,22 +14,43 @@ const (
 	ParamSourceQuery  ParamSource = "query"
 	ParamSourceOutput ParamSource = "output"
 	ParamSourcePath   ParamSource = "path"
+	ParamSourceHeader ParamSource = "header"
 )
 
+// paramTagSources are the struct tags that pin a field to a request source,
+// checked in order; untagged fields belong to the struct's default source.
+var paramTagSources = [...]ParamSource{ParamSourcePath, ParamSourceQuery, ParamSourceHeader}
+
 // RouteParam describes a single declared parameter on a route.
 type RouteParam struct {
-	Name     string
-	Type     string
-	Required bool
-	Source   ParamSource
+	Name     string      `json:"name" yaml:"name"`
+	Type     string      `json:"type" yaml:"type"`
+	Required bool        `json:"required" yaml:"required"`
+	Source   ParamSource `json:"source" yaml:"source"`
+	Rules    []string    `json:"rules,omitempty" yaml:"rules,omitempty"`
 }
 
 type RouteParamSet []RouteParam
 
 // RouteDoc holds routing documentation metadata.
 type RouteDoc struct {
+	name        string
 	description string
 	params      RouteParamSet
+
+	// Reflected struct types kept for schema generation (see Route.OpenAPI)
+	input  reflect.Type
+	query  reflect.Type
+	output reflect.Type
+}
+
+// Name sets the route name used for reverse routing (see Route.URLFor).
+func (d *RouteDoc) Name(name string) *RouteDoc {
+	if d == nil {
+		d = &RouteDoc{}
+	}
+	d.name = name
+	return d
 }
 
 // Input sets the input schema by reflecting over the provided struct instance.
@@ -37,6 +59,7 @@ func (d *RouteDoc) Input(v any) *RouteDoc {
 		d = &RouteDoc{}
 	}
 	d.params = append(d.params, paramsFromAny(v, ParamSourceInput)...)
+	d.input = valueType(v)
 	return d
 }
 
@@ -46,6 +69,7 @@ func (d *RouteDoc) Query(v any) *RouteDoc {
 		d = &RouteDoc{}
 	}
 	d.params = append(d.params, paramsFromAny(v, ParamSourceQuery)...)
+	d.query = structType(v)
 	return d
 }
 
@@ -55,12 +79,40 @@ func (d *RouteDoc) Output(v any) *RouteDoc {
 		d = &RouteDoc{}
 	}
 	d.params = append(d.params, paramsFromAny(v, ParamSourceOutput)...)
+	d.output = valueType(v)
 	return d
 }
 
 // Describe initializes a RouteDoc with a description.
 func Describe(desc string) *RouteDoc { return &RouteDoc{description: desc} }
 
+// Description returns the route description.
+func (d *RouteDoc) Description() string {
+	if d == nil {
+		return ""
+	}
+	return d.description
+}
+
+// RouteName returns the name set through Name or Named.
+func (d *RouteDoc) RouteName() string {
+	if d == nil {
+		return ""
+	}
+	return d.name
+}
+
+// Params returns the declared input, query and output parameters.
+func (d *RouteDoc) Params() RouteParamSet {
+	if d == nil {
+		return nil
+	}
+	return d.params
+}
+
+// Named is a convenience starting point for RouteDoc carrying only a route name.
+func Named(name string) *RouteDoc { return &RouteDoc{name: name} }
+
 // Input is a convenience starting point for RouteDoc without a description.
 func Input(v any) *RouteDoc { return (&RouteDoc{}).Input(v) }
 
@@ -71,71 +123,170 @@ func Query(v any) *RouteDoc { return (&RouteDoc{}).Query(v) }
 func Output(v any) *RouteDoc { return (&RouteDoc{}).Output(v) }
 
 func paramsFromAny(v any, source ParamSource) RouteParamSet {
-	if v == nil {
+	t := structType(v)
+	if t == nil {
 		return nil
 	}
 
-	t := reflect.TypeOf(v)
-	if t == nil {
+	return paramsFromType(t, source)
+}
+
+// structType returns the struct type behind v (dereferencing a pointer), or nil.
+func structType(v any) reflect.Type {
+	t := valueType(v)
+	if t == nil || t.Kind() != reflect.Struct {
 		return nil
 	}
 
+	return t
+}
+
+// valueType returns the type of v with a pointer dereferenced, or nil.
+func valueType(v any) reflect.Type {
+	if v == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(v)
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
 
-	if t.Kind() != reflect.Struct {
-		return nil
+	return t
+}
+
+func paramsFromType(t reflect.Type, source ParamSource) RouteParamSet {
+	fields := structFields(t, source)
+	params := make(RouteParamSet, 0, len(fields))
+
+	for _, f := range fields {
+		params = append(params, RouteParam{
+			Name:     f.name,
+			Type:     f.field.Type.String(),
+			Required: isRouteParamRequired(f.field),
+			Source:   f.source,
+			Rules:    routeParamRules(f.field),
+		})
 	}
 
-	return paramsFromType(t, source)
+	return params
 }
 
-func paramsFromType(t reflect.Type, source ParamSource) RouteParamSet {
-	params := make(RouteParamSet, 0, t.NumField())
+// structField is an exported struct field resolved to its request source
+// and parameter name. It is shared by RouteDoc, request binding and OpenAPI
+// generation so all three agree on names.
+type structField struct {
+	field  reflect.StructField
+	index  []int
+	name   string
+	source ParamSource
+}
+
+type structFieldsKey struct {
+	t      reflect.Type
+	source ParamSource
+}
 
+var structFieldsCache sync.Map // structFieldsKey -> []structField
+
+// structFields lists the exported fields of t, flattening embedded structs.
+// A path, query or header tag pins a field to that source; other fields
+// belong to source and are named from the json tag (query tag for query
+// structs), falling back to the lowercase field name. Fields tagged "-"
+// are excluded.
+func structFields(t reflect.Type, source ParamSource) []structField {
+	key := structFieldsKey{t, source}
+	if v, ok := structFieldsCache.Load(key); ok {
+		return v.([]structField)
+	}
+
+	fields := appendStructFields(nil, t, source, nil)
+	v, _ := structFieldsCache.LoadOrStore(key, fields)
+	return v.([]structField)
+}
+
+func appendStructFields(ret []structField, t reflect.Type, source ParamSource, index []int) []structField {
 	for i := range t.NumField() {
 		field := t.Field(i)
+		fieldIndex := append(index[:len(index):len(index)], i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			ret = appendStructFields(ret, field.Type, source, fieldIndex)
+			continue
+		}
 
 		if !field.IsExported() {
 			continue
 		}
 
-		// Resolve name from json tag, falling back to lowercase field name.
-		name := field.Tag.Get("json")
-		if name == "" {
-			name = strings.ToLower(field.Name)
-		} else {
-			name = strings.Split(name, ",")[0]
-			if name == "-" {
-				continue // explicitly excluded
+		f := structField{field: field, index: fieldIndex, source: source}
+		for _, src := range paramTagSources {
+			if tag, ok := field.Tag.Lookup(string(src)); ok {
+				f.name, _, _ = strings.Cut(tag, ",")
+				f.source = src
+				break
 			}
 		}
 
-		params = append(params, RouteParam{
-			Name:     name,
-			Type:     field.Type.String(),
-			Required: isRouteParamRequired(field),
-			Source:   source,
-		})
+		if f.source == source {
+			f.name = fieldParamName(field, source)
+		}
+
+		if f.name == "" || f.name == "-" {
+			continue
+		}
+
+		ret = append(ret, f)
 	}
 
-	return params
+	return ret
 }
 
-// isRouteParamRequired checks required:"true" and validate:"required" tags.
-func isRouteParamRequired(field reflect.StructField) bool {
-	if field.Tag.Get("required") == "true" {
-		return true
+// fieldParamName resolves the name of an untagged field for source.
+func fieldParamName(field reflect.StructField, source ParamSource) string {
+	keys := []string{"json"}
+	if source == ParamSourceQuery {
+		keys = []string{"query", "json"}
 	}
 
-	for _, part := range strings.Split(field.Tag.Get("validate"), ",") {
-		if strings.TrimSpace(part) == "required" {
-			return true
+	for _, key := range keys {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			if name, _, _ := strings.Cut(tag, ","); name != "" {
+				return name
+			}
 		}
 	}
 
-	return false
+	return strings.ToLower(field.Name)
+}
+
+// isRouteParamRequired checks required:"true" and validate:"required" tags,
+// using the same parser Validate enforces.
+func isRouteParamRequired(field reflect.StructField) bool {
+	fr := rulesOf(field)
+	return fr.required()
+}
+
+// routeParamRules lists the validation rules of field other than required
+// and omitempty.
+func routeParamRules(field reflect.StructField) []string {
+	fr := rulesOf(field)
+
+	var ret []string
+	for _, r := range fr.rules {
+		if r.name != "required" && r.name != "omitempty" {
+			ret = append(ret, r.String())
+		}
+	}
+	if fr.elem != nil {
+		ret = append(ret, "dive")
+		for _, r := range fr.elem.rules {
+			if r.name != "omitempty" {
+				ret = append(ret, r.String())
+			}
+		}
+	}
+	return ret
 }
 
 // formatRouteDoc formats a RouteDoc for display in the route list.
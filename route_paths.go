@@ -0,0 +1,96 @@
+package golly
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// PathPolicy decides how the router treats request paths that are not in
+// canonical form: duplicate slashes, "." or ".." segments, or a trailing slash.
+type PathPolicy uint8
+
+const (
+	// PathLenient routes non-canonical paths as if they were cleaned,
+	// so "/users/" and "//users/../users" both serve "/users". Default.
+	PathLenient PathPolicy = iota
+
+	// PathStrict answers non-canonical paths with 404.
+	PathStrict
+
+	// PathRedirect redirects non-canonical paths to their canonical form,
+	// using 301 for GET/HEAD and 308 for everything else so the method and
+	// body are preserved.
+	PathRedirect
+)
+
+// UsePathPolicy sets the path policy for the whole router.
+func (re *Route) UsePathPolicy(policy PathPolicy) *Route {
+	re.appRoot().pathPolicy = policy
+	return re
+}
+
+// isCleanPath reports whether p is already canonical. Allocation free so
+// the common case costs a single scan.
+func isCleanPath(p string) bool {
+	if p == "/" {
+		return true
+	}
+
+	if p == "" || p[0] != '/' || p[len(p)-1] == '/' {
+		return false
+	}
+
+	// Check every segment between slashes for "", "." and ".."
+	start := 1
+	for i := 1; i <= len(p); i++ {
+		if i < len(p) && p[i] != '/' {
+			continue
+		}
+
+		switch p[start:i] {
+		case "", ".", "..":
+			return false
+		}
+		start = i + 1
+	}
+
+	return true
+}
+
+// cleanPath returns the canonical form of p: rooted, "." and ".." resolved,
+// duplicate and trailing slashes removed.
+func cleanPath(p string) string {
+	if p == "" || p[0] != '/' {
+		p = "/" + p
+	}
+	return path.Clean(p)
+}
+
+// canonicalRedirect sends the client to the canonical form of the request path.
+// The target is re-escaped so "%3F", "%2F" and friends survive the round trip,
+// and never starts with "//" or "/\", which clients read as another host.
+func canonicalRedirect(w http.ResponseWriter, r *http.Request, clean string) {
+	code := http.StatusPermanentRedirect
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+
+	target := (&url.URL{Path: clean}).EscapedPath()
+	if raw := r.URL.RawPath; raw != "" && cleanPath(raw) != raw {
+		// The decoded path has lost escapes like "%2F"; clean the raw one
+		target = cleanPath(raw)
+	}
+
+	for strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		target = target[1:]
+	}
+
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+
+	w.Header().Set("Location", target)
+	w.WriteHeader(code)
+}
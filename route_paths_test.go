@@ -0,0 +1,154 @@
+package golly
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsCleanPath(t *testing.T) {
+	for p, expected := range map[string]bool{
+		"/":               true,
+		"/users":          true,
+		"/users/42/posts": true,
+		"/users/.hidden":  true,
+		"/users/...":      true,
+		"":                false,
+		"users":           false,
+		"/users/":         false,
+		"//users":         false,
+		"/users//42":      false,
+		"/users/./42":     false,
+		"/users/../admin": false,
+		"/users/..":       false,
+		"/users/.":        false,
+	} {
+		assert.Equal(t, expected, isCleanPath(p), p)
+		if expected {
+			assert.Equal(t, p, cleanPath(p), "clean paths should survive cleaning: %s", p)
+		}
+	}
+}
+
+func TestPathPolicy(t *testing.T) {
+	setup := func(policy PathPolicy) *Application {
+		app := NewApplication(Options{})
+		app.routes.UsePathPolicy(policy)
+		app.routes.
+			Get("/users", func(wctx *WebContext) { wctx.RenderText("users") }).
+			Get("/users/{id}", func(wctx *WebContext) { wctx.RenderText("user:" + wctx.URLParams().Get("id")) }).
+			Post("/users", func(wctx *WebContext) { wctx.RenderText("created") }).
+			Get("/admin", func(wctx *WebContext) { wctx.RenderText("admin") })
+		return app
+	}
+
+	type expectation struct {
+		status   int
+		body     string
+		location string
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		lenient  expectation
+		strict   expectation
+		redirect expectation
+	}{
+		{
+			name:     "canonical path",
+			method:   http.MethodGet,
+			target:   "/users",
+			lenient:  expectation{status: http.StatusOK, body: "users"},
+			strict:   expectation{status: http.StatusOK, body: "users"},
+			redirect: expectation{status: http.StatusOK, body: "users"},
+		},
+		{
+			name:     "trailing slash",
+			method:   http.MethodGet,
+			target:   "/users/",
+			lenient:  expectation{status: http.StatusOK, body: "users"},
+			strict:   expectation{status: http.StatusNotFound},
+			redirect: expectation{status: http.StatusMovedPermanently, location: "/users"},
+		},
+		{
+			name:     "duplicate slashes",
+			method:   http.MethodGet,
+			target:   "//users//42",
+			lenient:  expectation{status: http.StatusOK, body: "user:42"},
+			strict:   expectation{status: http.StatusNotFound},
+			redirect: expectation{status: http.StatusMovedPermanently, location: "/users/42"},
+		},
+		{
+			name:     "dot segments",
+			method:   http.MethodGet,
+			target:   "//users/../admin?x=1",
+			lenient:  expectation{status: http.StatusOK, body: "admin"},
+			strict:   expectation{status: http.StatusNotFound},
+			redirect: expectation{status: http.StatusMovedPermanently, location: "/admin?x=1"},
+		},
+		{
+			name:     "non-GET redirects preserve method",
+			method:   http.MethodPost,
+			target:   "/users/",
+			lenient:  expectation{status: http.StatusOK, body: "created"},
+			strict:   expectation{status: http.StatusNotFound},
+			redirect: expectation{status: http.StatusPermanentRedirect, location: "/users"},
+		},
+	}
+
+	for _, tt := range tests {
+		for policy, exp := range map[PathPolicy]expectation{
+			PathLenient:  tt.lenient,
+			PathStrict:   tt.strict,
+			PathRedirect: tt.redirect,
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(tt.method, "/", nil)
+				req.URL.Path, req.URL.RawQuery, _ = strings.Cut(tt.target, "?")
+
+				w := httptest.NewRecorder()
+				RouteRequest(setup(policy), req, w)
+
+				assert.Equal(t, exp.status, w.Code, "policy %d", policy)
+				assert.Equal(t, exp.body, w.Body.String(), "policy %d", policy)
+				assert.Equal(t, exp.location, w.Header().Get("Location"), "policy %d", policy)
+			})
+		}
+	}
+}
+
+func TestCanonicalRedirect(t *testing.T) {
+	app := NewApplication(Options{})
+	app.routes.UsePathPolicy(PathRedirect)
+
+	tests := []struct {
+		name     string
+		path     string
+		rawPath  string
+		location string
+	}{
+		{name: "it should re-escape reserved characters", path: "/files/a?b#c d/", location: "/files/a%3Fb%23c%20d"},
+		{name: "it should keep escaped slashes", path: "/files/a/b/", rawPath: "/files/a%2Fb/", location: "/files/a%2Fb"},
+		{name: "it should not redirect to another host", path: "//evil.com/x/..", location: "/evil.com"},
+		{name: "it should not redirect to a backslash host", path: "/\\evil.com/x/..", location: "/%5Cevil.com"},
+		{name: "it should collapse leading slashes in the raw path", path: "//evil.com/a/b/", rawPath: "//evil.com/a%2Fb/", location: "/evil.com/a%2Fb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.URL.Path, req.URL.RawPath = tt.path, tt.rawPath
+
+			w := httptest.NewRecorder()
+			RouteRequest(app, req, w)
+
+			assert.Equal(t, http.StatusMovedPermanently, w.Code)
+			assert.Equal(t, tt.location, w.Header().Get("Location"))
+		})
+	}
+}
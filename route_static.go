@@ -0,0 +1,266 @@
+package golly
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StaticOptions configures Route.Static.
+type StaticOptions struct {
+	// Index is served for the mount root and directories (default "index.html")
+	Index string
+
+	// SPA serves Index for missing paths without a file extension, so
+	// client-side routes resolve while missing assets still 404
+	SPA bool
+
+	// CacheControl is set on every file but Index, which is always
+	// revalidated ("no-cache") so new deploys are picked up
+	CacheControl string
+
+	// Precompressed serves a name.br or name.gz sibling instead of name when
+	// the client accepts that encoding
+	Precompressed bool
+}
+
+// precompressedEncodings are tried in order of preference.
+var precompressedEncodings = [...]struct{ encoding, ext string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// Static serves the files of fsys under prefix for GET and HEAD, through
+// ServeContent (so Range and conditional requests work) with a content
+// hash ETag. Paths are cleaned and anything escaping fsys is rejected.
+// Routes are registered on re, so namespaces, groups and middleware apply
+// as for any handler; misses go to the namespace NotFound handler when one
+// is set.
+//
+// Example:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	sub, _ := fs.Sub(dist, "dist")
+//	app.Routes().Static("/app", sub, golly.StaticOptions{
+//	    SPA:           true,
+//	    CacheControl:  "public, max-age=31536000, immutable",
+//	    Precompressed: true,
+//	})
+func (re *Route) Static(prefix string, fsys fs.FS, opts ...StaticOptions) *Route {
+	var opt StaticOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Index == "" {
+		opt.Index = "index.html"
+	}
+
+	s := &staticFS{fsys: fsys, opts: opt}
+
+	prefix = strings.TrimSuffix(prefix, "/")
+	root := prefix
+	if root == "" {
+		root = "/"
+	}
+
+	return re.
+		Get(root, s.serve).
+		Head(root, s.serve).
+		Get(prefix+"/*", s.serve).
+		Head(prefix+"/*", s.serve)
+}
+
+type staticFS struct {
+	fsys fs.FS
+	opts StaticOptions
+
+	etags sync.Map // staticETagKey -> string
+}
+
+type staticETagKey struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (s *staticFS) serve(wctx *WebContext) {
+	name, ok := staticPath(wctx.URLParams().Get(CatchAllKey))
+	if !ok {
+		s.notFound(wctx)
+		return
+	}
+
+	if name == "" || s.isDir(name) {
+		name = path.Join(name, s.opts.Index)
+	}
+
+	f, info, err := openFile(s.fsys, name)
+	if err != nil && s.opts.SPA && path.Ext(name) == "" {
+		name = s.opts.Index
+		f, info, err = openFile(s.fsys, name)
+	}
+	if err != nil {
+		if AsError(err).Status() == http.StatusNotFound {
+			s.notFound(wctx)
+			return
+		}
+		RenderError(wctx, err)
+		return
+	}
+	defer f.Close()
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		RenderError(wctx, fs.ErrInvalid)
+		return
+	}
+
+	// The type of the original file, not of a .br/.gz sibling
+	h := wctx.writer.Header()
+	served := name
+	if ct, err := contentTypeOf(name, content); err == nil {
+		h.Set("Content-Type", ct)
+	}
+
+	if s.opts.Precompressed {
+		addVary(h, "Accept-Encoding")
+
+		if cf, cinfo, encoding, cname := s.openPrecompressed(wctx.request, name); cf != nil {
+			defer cf.Close()
+
+			content, info, served = cf.(io.ReadSeeker), cinfo, cname
+			h.Set("Content-Encoding", encoding)
+		}
+	}
+
+	// Indexes, including those of subdirectories, are revalidated
+	if path.Base(name) == s.opts.Index {
+		h.Set("Cache-Control", "no-cache")
+	} else if s.opts.CacheControl != "" {
+		h.Set("Cache-Control", s.opts.CacheControl)
+	}
+
+	etag, err := s.etag(content, served, info)
+	if err != nil {
+		RenderError(wctx, err)
+		return
+	}
+	h.Set("ETag", etag)
+
+	wctx.ServeContent(name, info.ModTime(), content, 0)
+}
+
+// notFound runs the NotFound handler of the namespace, or answers 404.
+func (s *staticFS) notFound(wctx *WebContext) {
+	if wctx.route != nil {
+		if h := wctx.route.resolveFallback(func(r *Route) HandlerFunc { return r.notFoundHandler }); h != nil {
+			h(wctx)
+			return
+		}
+	}
+	RenderError(wctx, NewError(http.StatusNotFound, ErrFileNotFound))
+}
+
+func (s *staticFS) isDir(name string) bool {
+	info, err := fs.Stat(s.fsys, name)
+	return err == nil && info.IsDir()
+}
+
+// openPrecompressed opens the preferred sibling of name the client
+// accepts, returning its encoding and path.
+func (s *staticFS) openPrecompressed(r *http.Request, name string) (fs.File, fs.FileInfo, string, string) {
+	accept := r.Header.Values("Accept-Encoding")
+	if len(accept) == 0 {
+		return nil, nil, "", ""
+	}
+
+	for _, pc := range precompressedEncodings {
+		if !acceptsEncoding(accept, pc.encoding) {
+			continue
+		}
+
+		if f, info, err := openFile(s.fsys, name+pc.ext); err == nil {
+			if _, ok := f.(io.ReadSeeker); ok {
+				return f, info, pc.encoding, name + pc.ext
+			}
+			f.Close()
+		}
+	}
+	return nil, nil, "", ""
+}
+
+// etag returns the strong ETag of a file, hashing its content once per
+// name, size and modification time.
+func (s *staticFS) etag(content io.ReadSeeker, name string, info fs.FileInfo) (string, error) {
+	key := staticETagKey{name: name, size: info.Size(), modTime: info.ModTime()}
+	if v, ok := s.etags.Load(key); ok {
+		return v.(string), nil
+	}
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum.Sum(nil)[:16]) + `"`
+	s.etags.Store(key, etag)
+	return etag, nil
+}
+
+// staticPath cleans a catch-all value into an fs.FS path, rejecting
+// anything that would escape the root.
+func staticPath(p string) (string, bool) {
+	if strings.ContainsAny(p, "\\\x00") {
+		return "", false
+	}
+
+	for seg := range strings.SplitSeq(p, "/") {
+		if seg == ".." {
+			return "", false
+		}
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if name == "" {
+		return "", true
+	}
+	return name, fs.ValidPath(name)
+}
+
+// acceptsEncoding reports whether Accept-Encoding values allow encoding,
+// explicitly or through "*", with a non-zero quality.
+func acceptsEncoding(values []string, encoding string) bool {
+	star := -1.0
+	for _, v := range values {
+		for part := range strings.SplitSeq(v, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+			q := 1.0
+			if qs, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if f, err := strconv.ParseFloat(qs, 64); err == nil {
+					q = f
+				}
+			}
+
+			switch name = strings.TrimSpace(name); {
+			case strings.EqualFold(name, encoding):
+				return q > 0
+			case name == "*":
+				star = q
+			}
+		}
+	}
+	return star > 0
+}
@@ -0,0 +1,149 @@
+package golly
+
+import (
+	"io/fs"
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func staticTestFS() fstest.MapFS {
+	return fstest.MapFS{
+		"index.html":         {Data: []byte("<html>app</html>")},
+		"assets/app.js":      {Data: []byte("console.log('app')")},
+		"assets/app.js.br":   {Data: []byte("br-bytes")},
+		"assets/app.js.gz":   {Data: []byte("gz-bytes")},
+		"assets/style.css":   {Data: []byte("body{}")},
+		"docs/index.html":    {Data: []byte("<html>docs</html>")},
+		"other/app.js":       {Data: []byte("console.log('other')")},
+		"private/secret.txt": {Data: []byte("secret")},
+	}
+}
+
+func TestRouteStatic(t *testing.T) {
+	mount := func(opts StaticOptions) func(r *Route) {
+		return func(r *Route) { r.Static("/app", staticTestFS(), opts) }
+	}
+
+	t.Run("it should serve files under the prefix", func(t *testing.T) {
+		rec := serveTestApp(t, Options{}, mount(StaticOptions{CacheControl: "public, max-age=60"}), testRequest(http.MethodGet, "/app/assets/style.css", nil, nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "body{}", rec.Body.String())
+		assert.Equal(t, "text/css; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, rec.Header().Get("ETag"))
+	})
+
+	t.Run("it should serve the index for the root and directories", func(t *testing.T) {
+		rec := serveTestApp(t, Options{}, mount(StaticOptions{CacheControl: "public, max-age=60"}), testRequest(http.MethodGet, "/app", nil, nil))
+		assert.Equal(t, "<html>app</html>", rec.Body.String())
+		assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+
+		rec = serveTestApp(t, Options{}, mount(StaticOptions{CacheControl: "public, max-age=60"}), testRequest(http.MethodGet, "/app/docs/", nil, nil))
+		assert.Equal(t, "<html>docs</html>", rec.Body.String())
+		assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	})
+
+	t.Run("it should fall back to the index for client-side routes", func(t *testing.T) {
+		rec := serveTestApp(t, Options{}, mount(StaticOptions{SPA: true}), testRequest(http.MethodGet, "/app/orders/42", nil, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "<html>app</html>", rec.Body.String())
+
+		rec = serveTestApp(t, Options{}, mount(StaticOptions{SPA: true}), testRequest(http.MethodGet, "/app/assets/missing.js", nil, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = serveTestApp(t, Options{}, mount(StaticOptions{}), testRequest(http.MethodGet, "/app/orders/42", nil, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("it should serve precompressed siblings", func(t *testing.T) {
+		opts := StaticOptions{Precompressed: true}
+
+		rec := serveTestApp(t, Options{}, mount(opts), testRequest(http.MethodGet, "/app/assets/app.js", nil, map[string]string{"Accept-Encoding": "gzip, br"}))
+		assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "br-bytes", rec.Body.String())
+		assert.Equal(t, "text/javascript; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Header().Values("Vary"), "Accept-Encoding")
+		brETag := rec.Header().Get("ETag")
+
+		rec = serveTestApp(t, Options{}, mount(opts), testRequest(http.MethodGet, "/app/assets/app.js", nil, map[string]string{"Accept-Encoding": "gzip, br;q=0"}))
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "gz-bytes", rec.Body.String())
+		assert.NotEqual(t, brETag, rec.Header().Get("ETag"))
+
+		rec = serveTestApp(t, Options{}, mount(opts), testRequest(http.MethodGet, "/app/assets/app.js", nil, nil))
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "console.log('app')", rec.Body.String())
+	})
+
+	t.Run("it should key ETags by path", func(t *testing.T) {
+		a := serveTestApp(t, Options{}, mount(StaticOptions{}), testRequest(http.MethodGet, "/app/assets/app.js", nil, nil))
+		b := serveTestApp(t, Options{}, mount(StaticOptions{}), testRequest(http.MethodGet, "/app/other/app.js", nil, nil))
+
+		assert.NotEqual(t, a.Header().Get("ETag"), b.Header().Get("ETag"))
+
+		rec := serveTestApp(t, Options{}, mount(StaticOptions{}), testRequest(http.MethodGet, "/app/assets/app.js", nil, map[string]string{"If-None-Match": a.Header().Get("ETag")}))
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+	})
+
+	t.Run("it should answer HEAD and ranges", func(t *testing.T) {
+		rec := serveTestApp(t, Options{}, mount(StaticOptions{}), testRequest(http.MethodHead, "/app/assets/style.css", nil, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "6", rec.Header().Get("Content-Length"))
+		assert.Zero(t, rec.Body.Len())
+
+		rec = serveTestApp(t, Options{}, mount(StaticOptions{}), testRequest(http.MethodGet, "/app/assets/style.css", nil, map[string]string{"Range": "bytes=0-3"}))
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, "body", rec.Body.String())
+	})
+
+	t.Run("it should reject path traversal", func(t *testing.T) {
+		for _, p := range []string{"..", "../app/index.html", "a/../../x", `assets\app.js`, "assets/\x00.js"} {
+			_, ok := staticPath(p)
+			assert.False(t, ok, p)
+		}
+
+		for _, p := range []string{"/app/%2e%2e/private/secret.txt", "/app/..%2fprivate/secret.txt"} {
+			rec := serveTestApp(t, Options{}, func(r *Route) {
+				assets, err := fs.Sub(staticTestFS(), "assets")
+				require.NoError(t, err)
+				r.Static("/app", assets)
+			}, testRequest(http.MethodGet, p, nil, nil))
+
+			assert.Equal(t, http.StatusNotFound, rec.Code, p)
+			assert.NotContains(t, rec.Body.String(), "secret", p)
+		}
+	})
+
+	t.Run("it should work inside namespaces with middleware and NotFound", func(t *testing.T) {
+		var seen []string
+
+		setup := func(r *Route) {
+			r.Namespace("/ui", func(r *Route) {
+				r.Use(func(next HandlerFunc) HandlerFunc {
+					return func(wctx *WebContext) {
+						seen = append(seen, wctx.Path())
+						next(wctx)
+					}
+				})
+				r.NotFound(func(wctx *WebContext) {
+					wctx.WithStatus(http.StatusNotFound).RenderText("custom")
+				})
+				r.Static("/files", staticTestFS())
+			})
+		}
+
+		rec := serveTestApp(t, Options{}, setup, testRequest(http.MethodGet, "/ui/files/assets/style.css", nil, nil))
+		assert.Equal(t, "body{}", rec.Body.String())
+
+		rec = serveTestApp(t, Options{}, setup, testRequest(http.MethodGet, "/ui/files/missing.txt", nil, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "custom", rec.Body.String())
+
+		assert.Equal(t, []string{"/ui/files/assets/style.css", "/ui/files/missing.txt"}, seen)
+	})
+}
@@ -0,0 +1,150 @@
+package golly
+
+import (
+	"sort"
+	"strings"
+)
+
+// RouteInfo is a structured description of a single method on a route,
+// suitable for JSON/YAML export (gateway configs, contract tests).
+type RouteInfo struct {
+	Method      string         `json:"method" yaml:"method"`
+	Path        string         `json:"path" yaml:"path"`
+	Host        string         `json:"host,omitempty" yaml:"host,omitempty"`
+	Name        string         `json:"name,omitempty" yaml:"name,omitempty"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Vars        []RouteVarInfo `json:"vars,omitempty" yaml:"vars,omitempty"`
+	Handler     string         `json:"handler" yaml:"handler"`
+	Middleware  []string       `json:"middleware,omitempty" yaml:"middleware,omitempty"`
+	Params      RouteParamSet  `json:"params,omitempty" yaml:"params,omitempty"`
+
+	// Doc is the RouteDoc registered with the handler, if any
+	Doc *RouteDoc `json:"-" yaml:"-"`
+}
+
+// RouteVarInfo describes a dynamic path token.
+type RouteVarInfo struct {
+	Name     string `json:"name" yaml:"name"`
+	Matcher  string `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+	CatchAll bool   `json:"catch_all,omitempty" yaml:"catch_all,omitempty"`
+}
+
+// Table walks the route tree below (and including) this route and returns
+// every registered method, sorted by host, path and method. Host trees
+// mounted on the root are included with Host set.
+func (re *Route) Table() []RouteInfo {
+	node := re.node()
+
+	ret := node.collectTable(node.pattern(), nil, "")
+
+	for _, hr := range node.hosts {
+		ret = hr.tree.collectTable("/", ret, hr.pattern)
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Host != ret[j].Host {
+			return ret[i].Host < ret[j].Host
+		}
+		if ret[i].Path != ret[j].Path {
+			return ret[i].Path < ret[j].Path
+		}
+		return ret[i].Method < ret[j].Method
+	})
+
+	return ret
+}
+
+func (re *Route) collectTable(path string, ret []RouteInfo, host string) []RouteInfo {
+	if re.allowed != 0 {
+		middleware := re.resolveMiddleware()
+		vars := re.varInfo()
+
+		for name, mt := range methods {
+			if re.allowed&mt == 0 {
+				continue
+			}
+
+			idx := methodIndex(mt)
+			if re.handlers[idx] == nil {
+				idx = methodIndex(ALL)
+			}
+
+			doc := re.docs[idx]
+			info := RouteInfo{
+				Method:      name,
+				Path:        path,
+				Host:        host,
+				Name:        doc.RouteName(),
+				Description: doc.Description(),
+				Vars:        vars,
+				Params:      doc.Params(),
+				Doc:         doc,
+			}
+
+			if h := re.handlers[idx]; h != nil {
+				info.Handler = FuncPath(h)
+			}
+
+			for _, mw := range re.slotMiddleware(idx, middleware) {
+				info.Middleware = append(info.Middleware, FuncPath(mw))
+			}
+
+			ret = append(ret, info)
+		}
+	}
+
+	for _, child := range re.children {
+		childPath := path
+		if !strings.HasSuffix(childPath, "/") {
+			childPath += "/"
+		}
+		ret = child.collectTable(childPath+child.token.String(), ret, host)
+	}
+
+	return ret
+}
+
+// Pattern returns the full route pattern from the tree root to this route,
+// e.g. "/users/{id:int}".
+func (re *Route) Pattern() string { return re.pattern() }
+
+// pattern returns the full route pattern from the tree root to this route.
+func (re *Route) pattern() string {
+	var parts []string
+	for p := re; p != nil; p = p.parent {
+		if p.token != nil && p.token.value != "/" {
+			parts = append(parts, p.token.String())
+		}
+	}
+
+	if len(parts) == 0 {
+		return "/"
+	}
+
+	var b strings.Builder
+	for i := len(parts) - 1; i >= 0; i-- {
+		b.WriteByte('/')
+		b.WriteString(parts[i])
+	}
+	return b.String()
+}
+
+// varInfo lists the dynamic tokens from the tree root down to this route.
+func (re *Route) varInfo() []RouteVarInfo {
+	var ret []RouteVarInfo
+	for p := re; p != nil; p = p.parent {
+		if p.token != nil && p.token.isDynamic {
+			ret = append(ret, RouteVarInfo{
+				Name:     p.token.value,
+				Matcher:  p.token.matcher,
+				CatchAll: p.token.isCatchAll,
+			})
+		}
+	}
+
+	// Collected leaf first; report in path order
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+	return ret
+}
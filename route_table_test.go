@@ -0,0 +1,78 @@
+package golly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tableUserInput struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func tableMiddleware(next HandlerFunc) HandlerFunc { return next }
+
+func tableHandler(*WebContext) {}
+
+func TestRouteTable(t *testing.T) {
+	root := NewRouteRoot()
+	root.Use(tableMiddleware)
+
+	root.Namespace("/orgs/{org}", func(r *Route) {
+		r.Get("/users/{id:int}", tableHandler, Describe("show user").Name("users.show"))
+		r.Post("/users", tableHandler, Input(tableUserInput{}))
+		r.Get("/files/{path...}", tableHandler)
+	})
+	root.Host("{tenant}.example.com", func(r *Route) {
+		r.Get("/", tableHandler)
+	})
+
+	table := root.Table()
+	require.Len(t, table, 4)
+
+	t.Run("it should expose the full pattern of a route", func(t *testing.T) {
+		assert.Equal(t, "/orgs/{org}/users/{id:int}", root.Named("users.show").Pattern())
+		assert.Equal(t, "/", root.Pattern())
+	})
+
+	t.Run("it should sort by host, path and method", func(t *testing.T) {
+		var got []string
+		for _, info := range table {
+			got = append(got, info.Host+" "+info.Method+" "+info.Path)
+		}
+
+		assert.Equal(t, []string{
+			" GET /orgs/{org}/files/{path...}",
+			" POST /orgs/{org}/users",
+			" GET /orgs/{org}/users/{id:int}",
+			"{tenant}.example.com GET /",
+		}, got)
+	})
+
+	t.Run("it should describe vars, handlers and middleware", func(t *testing.T) {
+		show := table[2]
+
+		assert.Equal(t, "users.show", show.Name)
+		assert.Equal(t, "show user", show.Description)
+		assert.Equal(t, []RouteVarInfo{{Name: "org"}, {Name: "id", Matcher: "int"}}, show.Vars)
+		assert.Equal(t, FuncPath(tableHandler), show.Handler)
+		assert.Equal(t, []string{FuncPath(tableMiddleware)}, show.Middleware)
+
+		files := table[0]
+		assert.Equal(t, RouteVarInfo{Name: "path", CatchAll: true}, files.Vars[1])
+	})
+
+	t.Run("it should carry the RouteDoc", func(t *testing.T) {
+		create := table[1]
+
+		require.NotNil(t, create.Doc)
+		assert.Equal(t, RouteParamSet{{Name: "name", Type: "string", Required: true, Source: ParamSourceInput}}, create.Params)
+	})
+
+	t.Run("it should describe a subtree with its full path", func(t *testing.T) {
+		sub := FindRoute(root, "/orgs/acme/users").parent.Table()
+		assert.Len(t, sub, 3)
+		assert.Equal(t, "/orgs/{org}/files/{path...}", sub[0].Path)
+	})
+}
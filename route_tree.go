@@ -2,6 +2,7 @@ package golly
 
 import (
 	"fmt"
+	"iter"
 	"math/bits"
 	"net/http"
 	"sort"
@@ -27,8 +28,14 @@ type RouteVars struct {
 // Get returns the value for the given key, or empty string if not found.
 // Fully zero-alloc using string views and ASCIICompair.
 func (rv *RouteVars) Get(key string) string {
+	v, _ := rv.lookup(key)
+	return v
+}
+
+// lookup returns the value for the given key and whether it was present.
+func (rv *RouteVars) lookup(key string) (string, bool) {
 	if rv == nil {
-		return ""
+		return "", false
 	}
 
 	// Fast path: check fixed buffer
@@ -36,18 +43,18 @@ func (rv *RouteVars) Get(key string) string {
 
 	for i := 0; i < n; i++ {
 		if ASCIICompair(rv.keys[i], key) {
-			return rv.values[i]
+			return rv.values[i], true
 		}
 	}
 
 	// Slow path: check overflow (rare)
 	for i := 0; i < len(rv.kOverflow); i++ {
 		if ASCIICompair(rv.kOverflow[i], key) {
-			return rv.vOverflow[i]
+			return rv.vOverflow[i], true
 		}
 	}
 
-	return ""
+	return "", false
 }
 
 // set adds a key-value pair (internal, uses string views).
@@ -64,6 +71,25 @@ func (rv *RouteVars) set(key, value string) {
 	rv.count++
 }
 
+// All iterates the variables in path order.
+func (rv *RouteVars) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for i := range rv.Len() {
+			key, value := rv.at(i)
+			if !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+func (rv *RouteVars) at(i int) (string, string) {
+	if i < 8 {
+		return rv.keys[i], rv.values[i]
+	}
+	return rv.kOverflow[i-8], rv.vOverflow[i-8]
+}
+
 // Len returns the number of variables.
 func (rv *RouteVars) Len() int {
 	if rv == nil {
@@ -130,6 +156,28 @@ func fillRouteVariables(rv *RouteVars, re *Route, segments []string) {
 	// Second pass: extract variable values
 	p = re
 
+	// A catch-all is always the last node and swallows every segment
+	// past its own depth, so peel those off before the regular walk.
+	if p.token != nil && p.token.isCatchAll {
+		depth := p.depth()
+		if depth > len(segments) {
+			return
+		}
+
+		rest := segments[depth-1:]
+		if len(rest) == 1 {
+			rv.set(p.token.value, rest[0])
+		} else {
+			rv.set(p.token.value, strings.Join(rest, "/"))
+		}
+
+		segments = segments[:depth-1]
+		if p.parent == nil {
+			return
+		}
+		p = p.parent
+	}
+
 	// Walk backwards through the segments
 	for i := len(segments) - 1; i >= 0; i-- {
 		if p.token != nil && p.token.isDynamic {
@@ -176,16 +224,61 @@ type Route struct {
 	middleware []MiddlewareFunc //TBD
 
 	allowed methodType
+	implied methodType // Methods answered by AutoMethods, not registered handlers
 
 	allowHeader string // Precomputed Allow header
 
+	// groups records the Group view each handler was registered through;
+	// preflight holds the matching CORS no-op chains for grouped handlers
+	groups    [11]*Route
+	preflight [11]HandlerFunc
+
 	// Keep these here for runtime performance so we do not need to chain
 	// while running (need to think about how to handle this long term)
-	// methodNotAllowedHandler HandlerFunc
 	noOp HandlerFunc
 
+	// Fallbacks registered on this namespace (see NotFound/MethodNotAllowed)
+	// and their chained forms, resolved from the nearest ancestor that set one
+	notFoundHandler         HandlerFunc
+	methodNotAllowedHandler HandlerFunc
+	notFound                HandlerFunc
+	methodNotAllowed        HandlerFunc
+
+	// fallbacks is set on the application root once any fallback is
+	// registered, so plain 404s stay allocation free until then
+	fallbacks bool
+
+	// autoMethods and pathPolicy are set on the application root
+	// (see AutoMethods and UsePathPolicy)
+	autoMethods bool
+	pathPolicy  PathPolicy
+
 	parent *Route
 	root   *Route
+
+	// names maps route names to their nodes; only populated on the root
+	names map[string]*Route
+
+	// target and outer are only set on Group views: target is the tree
+	// node the view registers into, outer the enclosing group view
+	target *Route
+	outer  *Route
+
+	// hosts are host-pattern trees mounted on this root (see Route.Host).
+	// A host tree's own root points back through host and hostOf.
+	hosts  []*hostRoute
+	host   *hostRoute
+	hostOf *Route
+}
+
+// depth returns the number of segments consumed from the root to this route,
+// counting the root itself as the leading "/" segment.
+func (re *Route) depth() int {
+	cnt := 0
+	for p := re; p != nil; p = p.parent {
+		cnt++
+	}
+	return cnt
 }
 
 // FindChildByToken find a child given a route token
@@ -199,15 +292,19 @@ func (re Route) FindChildByToken(token *RouteToken) *Route {
 }
 
 func (re Route) Allow() []string {
+	return re.allowList(re.allowed)
+}
+
+func (re Route) allowList(allowed methodType) []string {
 	ret := []string{}
 
-	if re.allowed == 0 {
+	if allowed == 0 {
 		return ret
 	}
 
 	// Use stable ordering for cleanliness, though precomputed string is preferred now
 	for name, mt := range methods {
-		if re.allowed&mt != 0 {
+		if allowed&mt != 0 {
 			ret = append(ret, name)
 		}
 	}
@@ -265,6 +362,12 @@ func (re *Route) search(segments []string) *Route {
 		if !re.token.Match(&segments[0]) {
 			return nil
 		}
+
+		// Catch-alls consume whatever is left
+		if re.token.isCatchAll {
+			return re
+		}
+
 		// If there are more segments to match, proceed
 		if tkLen > 1 {
 			segments = segments[1:]
@@ -273,14 +376,24 @@ func (re *Route) search(segments []string) *Route {
 		}
 	}
 
-	// Continue searching in children
+	// Continue searching in children. A match without handlers (an inner
+	// node of a longer route) must not hide a lower precedence sibling
+	// such as a catch-all, so it is only kept as a last resort
+	var fallback *Route
 	for pos := range re.children {
-		if found := re.children[pos].search(segments); found != nil {
+		found := re.children[pos].search(segments)
+		if found == nil {
+			continue
+		}
+		if found.allowed != 0 {
 			return found
 		}
+		if fallback == nil {
+			fallback = found
+		}
 	}
 
-	return nil
+	return fallback
 }
 
 func (re Route) Length() (cnt int) {
@@ -309,11 +422,19 @@ func (re *Route) updateHandlers() {
 	// Resolve middleware once and apply to all handlers
 	middleware := re.resolveMiddleware()
 
-	// Update chained handlers
+	// Update chained handlers, clearing anything derived on a previous pass
+	re.chained = [11]HandlerFunc{}
+	re.preflight = [11]HandlerFunc{}
 	for i := range 11 {
 		h := re.handlers[i]
 		if h != nil {
-			re.chained[i] = chain(middleware, h)
+			mw := re.slotMiddleware(i, middleware)
+			re.chained[i] = chain(mw, h)
+
+			// Grouped handlers answer preflights through their own stack
+			if re.groups[i] != nil {
+				re.preflight[i] = chain(mw, noOpHandler)
+			}
 		}
 	}
 
@@ -321,45 +442,70 @@ func (re *Route) updateHandlers() {
 	// The original logic was: "Propagate ALL handler to ALL methods if they are empty"
 	// "if method == ALL { for _, mType := range methods ... }"
 	if allH := re.handlers[10]; allH != nil {
-		chainedAll := chain(middleware, allH)
-		re.chained[10] = chainedAll
+		chainedAll := re.chained[10]
 
 		for _, mType := range methods {
 			idx := methodIndex(mType)
 			if re.chained[idx] == nil {
 				re.chained[idx] = chainedAll
+				re.preflight[idx] = re.preflight[10]
 			}
 		}
 	}
 
+	re.implied = re.autoMethodHandlers(middleware)
+
 	for _, child := range re.children {
 		child.updateHandlers()
 	}
 
-	// re.methodNotAllowedHandler = chain(middleware, notAllowedHandler)
+	for _, hr := range re.hosts {
+		hr.tree.updateHandlers()
+	}
+
 	re.noOp = chain(middleware, noOpHandler)
+	re.notFound, re.methodNotAllowed = nil, nil
+
+	if h := re.resolveFallback(func(r *Route) HandlerFunc { return r.notFoundHandler }); h != nil {
+		re.notFound = chain(middleware, h)
+	}
+	if h := re.resolveFallback(func(r *Route) HandlerFunc { return r.methodNotAllowedHandler }); h != nil {
+		re.methodNotAllowed = chain(middleware, h)
+	}
 
 	// Precompute Allow header
-	re.allowHeader = strings.Join(re.Allow(), ",")
+	re.allowHeader = strings.Join(re.allowList(re.allowed|re.implied), ",")
 }
 
 func (re *Route) resolveMiddleware() []MiddlewareFunc {
-	middleware := []MiddlewareFunc{}
+	// Build a fresh slice rather than appending onto an ancestor's
+	// middleware, which would share (and may write into) its backing array
+	n := 0
+	for cur := re; cur != nil; cur = cur.ancestor() {
+		n += len(cur.middleware)
+	}
 
-	cur := re
-	for cur != nil {
-		// Prepend current route's middleware to the list
-		middleware = append(cur.middleware, middleware...)
+	middleware := make([]MiddlewareFunc, n)
 
-		// Move to the parent route
-		cur = cur.parent
+	// Fill from the end so the root's middleware ends up first
+	for cur := re; cur != nil; cur = cur.ancestor() {
+		n -= len(cur.middleware)
+		copy(middleware[n:], cur.middleware)
 	}
 
 	return middleware
 }
 
 func (re *Route) add(path string, handler HandlerFunc, httpMethods methodType, docs ...*RouteDoc) *Route {
+	// Group views register into the real tree, tagging handlers with the group
+	if re.target != nil {
+		return re.target.insert(path, handler, httpMethods, re, docs...)
+	}
+
+	return re.insert(path, handler, httpMethods, nil, docs...)
+}
 
+func (re *Route) insert(path string, handler HandlerFunc, httpMethods methodType, group *Route, docs ...*RouteDoc) *Route {
 	tokens := tokenize(path)
 	if len(tokens) == 0 {
 		return re
@@ -373,6 +519,14 @@ func (re *Route) add(path string, handler HandlerFunc, httpMethods methodType, d
 	}
 
 	for pos := range tokens {
+		if tokens[pos].isCatchAll && pos != len(tokens)-1 {
+			panic(fmt.Sprintf("golly: catch-all segment must be the last segment in route %q", path))
+		}
+
+		// Invalid matchers fail at boot instead of silently never matching
+		if err := tokens[pos].compile(); err != nil {
+			panic(fmt.Sprintf("golly: route %q: %v", path, err))
+		}
 
 		// Look for the token at the current level
 		if tokens[pos].value == "/" {
@@ -389,9 +543,9 @@ func (re *Route) add(path string, handler HandlerFunc, httpMethods methodType, d
 				node.token = token
 				r.children = append(r.children, node)
 
-				// Sort children: static routes first, then dynamic routes
-				sort.Slice(r.children, func(i, j int) bool {
-					return !r.children[i].token.isDynamic && r.children[j].token.isDynamic
+				// Sort children: static routes first, then dynamic routes, then catch-alls
+				sort.SliceStable(r.children, func(i, j int) bool {
+					return r.children[i].token.precedence() < r.children[j].token.precedence()
 				})
 			}
 			r = node
@@ -404,11 +558,16 @@ func (re *Route) add(path string, handler HandlerFunc, httpMethods methodType, d
 
 			if r.handlers[allIdx] == nil && r.handlers[idx] == nil {
 				r.handlers[idx] = handler
+				r.groups[idx] = group
 				if len(docs) > 0 {
 					r.docs[idx] = docs[0]
 				}
 				r.allowed |= httpMethods
 				r.updateHandlers()
+
+				if len(docs) > 0 && docs[0] != nil && docs[0].name != "" {
+					root.registerName(docs[0].name, r)
+				}
 			}
 		}
 	}
@@ -419,7 +578,7 @@ func (re *Route) add(path string, handler HandlerFunc, httpMethods methodType, d
 func (re *Route) Use(fns ...MiddlewareFunc) *Route {
 	re.middleware = append(re.middleware, fns...)
 
-	re.updateHandlers()
+	re.node().updateHandlers()
 
 	return re
 }
@@ -486,6 +645,11 @@ func (re *Route) Mount(path string, c Controller) *Route {
 func (re *Route) Namespace(path string, f func(r *Route)) *Route {
 	r := re.add(path, nil, 0, nil)
 
+	// Namespaces opened inside a group stay inside the group
+	if re.target != nil {
+		r = re.view(r)
+	}
+
 	f(r)
 
 	return re
@@ -498,26 +662,63 @@ func (re *Route) Route(path string, f func(r *Route)) *Route {
 func RouteRequest(a *Application, r *http.Request, w http.ResponseWriter) {
 	path := r.URL.Path
 
+	if len(path) == 0 {
+		w.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+
+	// Canonicalise before lookup so "/users/", "//users" and "/x/../users"
+	// resolve deterministically (canonical paths skip this allocation free)
+	canonical := isCleanPath(path)
+	if !canonical {
+		switch a.routes.pathPolicy {
+		case PathRedirect:
+			canonicalRedirect(w, r, cleanPath(path))
+			return
+		case PathLenient:
+			path = cleanPath(path)
+			canonical = true
+		}
+	}
+
 	// Pre-sized slice (length=capacity) for zero-alloc parsing
 	var stack = make([]string, makePathCount(path))
 
 	// Tokenize path for route lookup (returns count, int doesn't escape)
 	pathSegments(stack, path)
 
-	if len(path) == 0 {
-		w.WriteHeader(http.StatusNotAcceptable)
-		return
+	// Pick the host tree (if any) before path lookup
+	routes := a.routes
+	if host := routes.matchHost(r.Host); host != nil {
+		routes = host.tree
+	}
+
+	// Fast route lookup before any allocations; strict mode treats
+	// non-canonical paths as misses
+	var re *Route
+	if canonical {
+		re = FindRouteBySegments(routes, stack)
 	}
 
-	// Fast route lookup before any allocations
-	re := FindRouteBySegments(a.routes, stack)
 	if re == nil {
+		if a.routes.fallbacks {
+			if closest := closestRoute(routes, stack); closest.notFound != nil {
+				serveFallback(a, r, w, closest, stack[:closest.depth()], closest.notFound)
+				return
+			}
+		}
+
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
 
 	if re.allowed == 0 {
 		w.Header().Set(HeaderAllow, re.allowHeader)
+		if re.methodNotAllowed != nil {
+			serveFallback(a, r, w, re, stack, re.methodNotAllowed)
+			return
+		}
+
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
@@ -528,9 +729,7 @@ func RouteRequest(a *Application, r *http.Request, w http.ResponseWriter) {
 	defer a.wctxPool.Put(wctx)
 
 	wctx.route = re
-
-	fillRouteVariables(&wctx.vars, re, wctx.segments)
-	wctx.varsLoaded = true
+	wctx.loadVars()
 
 	// Resolve method for CORS preflight or actual request
 	method := r.Header.Get(HeaderAccessControlRequestMethod)
@@ -545,6 +744,11 @@ func RouteRequest(a *Application, r *http.Request, w http.ResponseWriter) {
 				handler(wctx)
 				return
 			}
+
+			if pf := re.preflight[idx]; pf != nil {
+				pf(wctx)
+				return
+			}
 			re.noOp(wctx)
 			return
 		}
@@ -552,6 +756,11 @@ func RouteRequest(a *Application, r *http.Request, w http.ResponseWriter) {
 
 	// Method not allowed after wctx allocated
 	w.Header().Set(HeaderAllow, re.allowHeader)
+	if re.methodNotAllowed != nil {
+		re.methodNotAllowed(wctx)
+		return
+	}
+
 	w.WriteHeader(http.StatusMethodNotAllowed)
 }
 
@@ -608,17 +817,7 @@ func buildPath(route *Route, prefix string) []string {
 				prefix += "/"
 			}
 
-			if route.token.isDynamic {
-				pattern := ""
-				if m := route.token.matcher; m != "" {
-					pattern = ":" + m
-				}
-
-				prefix += fmt.Sprintf("{%s%s}", route.token.value, pattern)
-
-			} else {
-				prefix += route.token.value
-			}
+			prefix += route.token.String()
 		}
 	}
 
@@ -80,6 +80,127 @@ func TestRouteVariables(t *testing.T) {
 	}
 }
 
+func TestCatchAllRoutes(t *testing.T) {
+	root := NewRouteRoot()
+
+	root.Get("/static/*", noOpHandler).
+		Get("/static/foo/x", noOpHandler).
+		Get("/docs/{section}/index", noOpHandler).
+		Get("/docs/{rest...}", noOpHandler).
+		Get("/files/{path...}", noOpHandler).
+		Get("/files/readme", noOpHandler).
+		Get("/files/{id:[0-9]+}", noOpHandler).
+		Get("/files/{id:[0-9]+}/meta", noOpHandler).
+		Get("/orgs/{org}/blobs/{key...}", noOpHandler)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+		vars     map[string]string
+	}{
+		{
+			name:     "anonymous catch-all single segment",
+			path:     "/static/app.js",
+			expected: "*",
+			vars:     map[string]string{"*": "app.js"},
+		},
+		{
+			name:     "anonymous catch-all many segments",
+			path:     "/static/js/vendor/app.js",
+			expected: "*",
+			vars:     map[string]string{"*": "js/vendor/app.js"},
+		},
+		{
+			name:     "named catch-all",
+			path:     "/files/a/b/c.txt",
+			expected: "path",
+			vars:     map[string]string{"path": "a/b/c.txt"},
+		},
+		{
+			name:     "static sibling wins",
+			path:     "/files/readme",
+			expected: "readme",
+			vars:     map[string]string{},
+		},
+		{
+			name:     "dynamic sibling wins",
+			path:     "/files/42",
+			expected: "id",
+			vars:     map[string]string{"id": "42"},
+		},
+		{
+			name:     "dynamic sibling subtree wins",
+			path:     "/files/42/meta",
+			expected: "meta",
+			vars:     map[string]string{"id": "42"},
+		},
+		{
+			name:     "falls back to catch-all when dynamic subtree misses",
+			path:     "/files/42/other",
+			expected: "path",
+			vars:     map[string]string{"path": "42/other"},
+		},
+		{
+			name:     "static sibling subtree wins",
+			path:     "/static/foo/x",
+			expected: "x",
+			vars:     map[string]string{},
+		},
+		{
+			name:     "falls back to catch-all when static node has no handlers",
+			path:     "/static/foo",
+			expected: "*",
+			vars:     map[string]string{"*": "foo"},
+		},
+		{
+			name:     "falls back to catch-all when dynamic node has no handlers",
+			path:     "/docs/intro",
+			expected: "rest",
+			vars:     map[string]string{"rest": "intro"},
+		},
+		{
+			name:     "catch-all after variables",
+			path:     "/orgs/acme/blobs/2024/report.pdf",
+			expected: "key",
+			vars:     map[string]string{"org": "acme", "key": "2024/report.pdf"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stack := make([]string, makePathCount(tt.path))
+			pathSegments(stack, tt.path)
+
+			route := FindRouteBySegments(root, stack)
+			if !assert.NotNil(t, route) {
+				return
+			}
+			assert.Equal(t, tt.expected, route.token.value)
+
+			vars := routeVariables(route, stack)
+			assert.Equal(t, len(tt.vars), vars.Len())
+			for key, expected := range tt.vars {
+				assert.Equal(t, expected, vars.Get(key))
+			}
+		})
+	}
+
+	t.Run("it should not match an empty remainder", func(t *testing.T) {
+		r := FindRoute(root, "/static")
+		if assert.NotNil(t, r) {
+			assert.False(t, r.token.isCatchAll)
+		}
+		assert.Nil(t, FindRoute(root, "/static/"))
+	})
+
+	t.Run("it should panic when the catch-all is not trailing", func(t *testing.T) {
+		assert.Panics(t, func() {
+			NewRouteRoot().Get("/static/*/edit", noOpHandler)
+		})
+	})
+}
+
 func TestUse(t *testing.T) {
 	root := NewRouteRoot()
 	root.Add("/test", noOpHandler, GET)
@@ -251,6 +372,19 @@ func TestBuildPath(t *testing.T) {
 				"[PUT]\t/api/v1/{userID:[0-9]+}\t\t-\t-\t-",
 			},
 		},
+		{
+			name: "Catch-all paths",
+			route: func() *Route {
+				root := NewRouteRoot()
+				root.Get("/static/*", func(ctx *WebContext) {})
+				root.Get("/files/{path...}", func(ctx *WebContext) {})
+				return root
+			}(),
+			expected: []string{
+				"[GET]\t/files/{path...}\t\t-\t-\t-",
+				"[GET]\t/static/*\t\t-\t-\t-",
+			},
+		},
 		{
 			name: "No allowed methods",
 			route: &Route{
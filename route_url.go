@@ -0,0 +1,115 @@
+package golly
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrRouteNameNotFound = errors.New("route name not found")
+	ErrMissingRouteVar   = errors.New("missing route variable")
+	ErrInvalidRouteVar   = errors.New("route variable does not match")
+)
+
+// registerName records a named route on the root; names must be unique per tree.
+func (re *Route) registerName(name string, r *Route) {
+	if re.names == nil {
+		re.names = map[string]*Route{}
+	}
+
+	if existing, ok := re.names[name]; ok && existing != r {
+		panic(fmt.Sprintf("golly: route name %q is already registered", name))
+	}
+
+	re.names[name] = r
+}
+
+// Named returns the route registered under name, or nil.
+func (re *Route) Named(name string) *Route {
+	root := re.root
+	if root == nil {
+		root = re
+	}
+	return root.names[name]
+}
+
+// URLFor builds the path of the route registered under name, substituting
+// {var} tokens from vars. Values are validated against the token matcher and
+// path-escaped; catch-all values keep their slashes.
+//
+// Example:
+//
+//	app.Routes().Namespace("/api/v1", func(r *golly.Route) {
+//	    r.Get("/users/{id:int}", showUser, golly.Named("users.show"))
+//	})
+//
+//	path, err := app.Routes().URLFor("users.show", map[string]string{"id": "42"})
+//	// path == "/api/v1/users/42"
+func (re *Route) URLFor(name string, vars map[string]string) (string, error) {
+	r := re.Named(name)
+	if r == nil {
+		return "", fmt.Errorf("%w: %s", ErrRouteNameNotFound, name)
+	}
+
+	return r.buildURL(vars)
+}
+
+// buildURL walks parent links from this route up to the root and joins the
+// resolved segments in order.
+func (re *Route) buildURL(vars map[string]string) (string, error) {
+	var segments []string
+
+	for p := re; p != nil; p = p.parent {
+		if p.token == nil || p.token.value == "/" {
+			continue
+		}
+
+		tok := p.token
+		if !tok.isDynamic {
+			segments = append(segments, tok.value)
+			continue
+		}
+
+		val, ok := vars[tok.value]
+		if !ok || val == "" {
+			return "", fmt.Errorf("%w: %s", ErrMissingRouteVar, tok.value)
+		}
+
+		if tok.isCatchAll {
+			parts := strings.Split(val, "/")
+			for i := range parts {
+				parts[i] = url.PathEscape(parts[i])
+			}
+			segments = append(segments, strings.Join(parts, "/"))
+			continue
+		}
+
+		if !tok.Match(&val) {
+			return "", fmt.Errorf("%w: %s=%q (%s)", ErrInvalidRouteVar, tok.value, val, tok.matcher)
+		}
+
+		segments = append(segments, url.PathEscape(val))
+	}
+
+	var b strings.Builder
+	for i := len(segments) - 1; i >= 0; i-- {
+		b.WriteByte('/')
+		b.WriteString(segments[i])
+	}
+
+	if b.Len() == 0 {
+		return "/", nil
+	}
+
+	return b.String(), nil
+}
+
+// URLFor builds the path for a named route in the tree serving this request.
+func (wctx *WebContext) URLFor(name string, vars map[string]string) (string, error) {
+	if wctx.route == nil {
+		return "", fmt.Errorf("%w: %s", ErrRouteNameNotFound, name)
+	}
+	return wctx.route.URLFor(name, vars)
+}
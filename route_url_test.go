@@ -0,0 +1,73 @@
+package golly
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLFor(t *testing.T) {
+	root := NewRouteRoot()
+
+	root.Get("/", noOpHandler, Named("home"))
+	root.Namespace("/api/v1", func(r *Route) {
+		r.Get("/users/{id:int}", noOpHandler, Named("users.show"))
+		r.Get("/users/{id:int}/posts/{slug}", noOpHandler, Describe("a post").Name("posts.show"))
+		r.Get("/files/{path...}", noOpHandler, Named("files"))
+	})
+
+	tests := []struct {
+		name     string
+		route    string
+		vars     map[string]string
+		expected string
+		err      error
+	}{
+		{name: "root", route: "home", expected: "/"},
+		{name: "namespaced", route: "users.show", vars: map[string]string{"id": "42"}, expected: "/api/v1/users/42"},
+		{
+			name:     "multiple vars with escaping",
+			route:    "posts.show",
+			vars:     map[string]string{"id": "42", "slug": "hello world"},
+			expected: "/api/v1/users/42/posts/hello%20world",
+		},
+		{name: "catch-all", route: "files", vars: map[string]string{"path": "a/b c.txt"}, expected: "/api/v1/files/a/b%20c.txt"},
+		{name: "unknown name", route: "nope", err: ErrRouteNameNotFound},
+		{name: "missing var", route: "users.show", vars: map[string]string{}, err: ErrMissingRouteVar},
+		{name: "invalid var", route: "users.show", vars: map[string]string{"id": "abc"}, err: ErrInvalidRouteVar},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := root.URLFor(tt.route, tt.vars)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, path)
+
+			// Generated paths must route back to the named route
+			assert.Same(t, root.Named(tt.route), FindRoute(root, path))
+		})
+	}
+
+	t.Run("it should panic on duplicate names", func(t *testing.T) {
+		assert.Panics(t, func() {
+			root.Get("/other", noOpHandler, Named("home"))
+		})
+	})
+
+	t.Run("it should resolve from the web context", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+		wctx := NewTestWebContext(req, httptest.NewRecorder())
+		wctx.route = FindRoute(root, req.URL.Path)
+
+		path, err := wctx.URLFor("users.show", map[string]string{"id": "7"})
+		assert.NoError(t, err)
+		assert.Equal(t, "/api/v1/users/7", path)
+	})
+}
@@ -1,21 +1,69 @@
 package golly
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 )
 
+// CatchAllKey is the RouteVars key used by an anonymous "*" catch-all segment.
+const CatchAllKey = "*"
+
+// matcherCache holds compiled matcher patterns keyed by their source so
+// routes sharing a pattern (e.g. {id:[0-9]+}) share one *regexp.Regexp.
+var matcherCache sync.Map // map[string]*regexp.Regexp
+
 type RouteToken struct {
-	value     string
-	matcher   string
-	isDynamic bool
+	value      string
+	matcher    string
+	isDynamic  bool
+	isCatchAll bool
+
+	// constraint is set when matcher names a registered RouteConstraint,
+	// otherwise re holds the compiled, anchored regex; both set by compile()
+	constraint RouteConstraint
+	re         *regexp.Regexp
 }
 
-func (rs *RouteToken) Value() string   { return rs.value }
-func (rs *RouteToken) Matcher() string { return rs.matcher }
-func (rs *RouteToken) IsDynamic() bool { return rs.isDynamic }
+func (rs *RouteToken) Value() string    { return rs.value }
+func (rs *RouteToken) Matcher() string  { return rs.matcher }
+func (rs *RouteToken) IsDynamic() bool  { return rs.isDynamic }
+func (rs *RouteToken) IsCatchAll() bool { return rs.isCatchAll }
 func (rs *RouteToken) Equal(rs2 *RouteToken) bool {
-	return rs2.value == rs.value
+	return rs2.value == rs.value &&
+		rs2.isDynamic == rs.isDynamic &&
+		rs2.isCatchAll == rs.isCatchAll
+}
+
+// String returns the token in route pattern form, e.g. "users",
+// "{id:[0-9]+}", "{path...}" or "*".
+func (rs *RouteToken) String() string {
+	switch {
+	case rs.isCatchAll && rs.value == CatchAllKey:
+		return CatchAllKey
+	case rs.isCatchAll:
+		return "{" + rs.value + "...}"
+	case rs.isDynamic && rs.matcher != "":
+		return "{" + rs.value + ":" + rs.matcher + "}"
+	case rs.isDynamic:
+		return "{" + rs.value + "}"
+	default:
+		return rs.value
+	}
+}
+
+// precedence orders sibling tokens during lookup: static segments are tried
+// first, then single-segment variables, then trailing catch-alls.
+func (rs *RouteToken) precedence() int {
+	switch {
+	case rs.isCatchAll:
+		return 2
+	case rs.isDynamic:
+		return 1
+	default:
+		return 0
+	}
 }
 
 // Match takes a string and matches it against hte current RouteToken
@@ -26,17 +74,73 @@ func (rs *RouteToken) Match(str *string) bool {
 		return rs.value == *str
 	}
 
+	// Catch-alls must capture at least one non-empty segment
+	if rs.isCatchAll {
+		return *str != ""
+	}
+
 	if rs.matcher == "" {
 		return true
 	}
 
-	matched, _ := regexp.MatchString(rs.matcher, *str)
-	return matched
+	// Tokens built outside of Route.add (tests, hand-rolled trees) compile lazily
+	if rs.constraint == nil && rs.re == nil {
+		if err := rs.compile(); err != nil {
+			return false
+		}
+	}
+
+	if rs.constraint != nil {
+		return rs.constraint(*str)
+	}
+
+	return rs.re.MatchString(*str)
+}
+
+// compile prepares the token's matcher once so Match never touches the
+// regexp parser on the request path. Named constraints ({id:int}) resolve
+// to their hand-written matcher; anything else is treated as a regex
+// anchored to the whole segment, so {id:[0-9]+} does not match "12abc".
+func (rs *RouteToken) compile() error {
+	if !rs.isDynamic || rs.matcher == "" || rs.re != nil || rs.constraint != nil {
+		return nil
+	}
+
+	if fn, ok := lookupRouteConstraint(rs.matcher); ok {
+		rs.constraint = fn
+		return nil
+	}
+
+	re, err := compileMatcher(rs.matcher)
+	if err != nil {
+		return err
+	}
+
+	rs.re = re
+	return nil
+}
+
+// compileMatcher returns the cached anchored regexp for pattern, compiling it on first use.
+func compileMatcher(pattern string) (*regexp.Regexp, error) {
+	if re, ok := matcherCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid route matcher %q: %w", pattern, err)
+	}
+
+	actual, _ := matcherCache.LoadOrStore(pattern, re)
+	return actual.(*regexp.Regexp), nil
 }
 
 // tokenize takes a string path and turns it into RouteTokens.
 // Optimized for hotpath: no []byte conversion, no per-token heap objects.
 // All strings are slices of the original path (zero alloc).
+//
+// A trailing "*" or "{name...}" segment becomes a catch-all token that
+// captures every remaining path segment.
 func tokenize(path string) []RouteToken {
 	if path == "" {
 		return nil
@@ -73,7 +177,15 @@ func tokenize(path string) []RouteToken {
 					}
 				}
 
-				if colon >= 0 {
+				name := path[start+1 : pos]
+
+				if colon < 0 && strings.HasSuffix(name, "...") {
+					tokens = append(tokens, RouteToken{
+						value:      name[:len(name)-3],
+						isDynamic:  true,
+						isCatchAll: true,
+					})
+				} else if colon >= 0 {
 					tokens = append(tokens, RouteToken{
 						value:     path[start+1 : colon],
 						isDynamic: true,
@@ -81,7 +193,7 @@ func tokenize(path string) []RouteToken {
 					})
 				} else {
 					tokens = append(tokens, RouteToken{
-						value:     path[start+1 : pos],
+						value:     name,
 						isDynamic: true,
 					})
 				}
@@ -101,7 +213,15 @@ func tokenize(path string) []RouteToken {
 			pos++
 		}
 		if start != pos {
-			tokens = append(tokens, RouteToken{value: path[start:pos]})
+			if pos-start == 1 && path[start] == '*' {
+				tokens = append(tokens, RouteToken{
+					value:      CatchAllKey,
+					isDynamic:  true,
+					isCatchAll: true,
+				})
+			} else {
+				tokens = append(tokens, RouteToken{value: path[start:pos]})
+			}
 		}
 
 		if pos < n && path[pos] == '/' {
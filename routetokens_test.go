@@ -111,6 +111,78 @@ func TestTokenizePath(t *testing.T) {
 	})
 }
 
+func TestTokenizeCatchAll(t *testing.T) {
+	tests := []struct {
+		url      string
+		value    string
+		catchAll bool
+	}{
+		{url: "/static/*", value: "*", catchAll: true},
+		{url: "/files/{path...}", value: "path", catchAll: true},
+		{url: "/files/{path}", value: "path", catchAll: false},
+		{url: "/files/*.txt", value: "*.txt", catchAll: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			tokens := tokenize(tt.url)
+
+			assert.Len(t, tokens, 3)
+
+			last := tokens[len(tokens)-1]
+			assert.Equal(t, tt.value, last.value)
+			assert.Equal(t, tt.catchAll, last.isCatchAll)
+		})
+	}
+}
+
+func TestRouteTokenMatcher(t *testing.T) {
+	t.Run("it should compile matchers once at registration", func(t *testing.T) {
+		root := NewRouteRoot()
+		root.Get("/users/{id:[0-9]+}", noOpHandler)
+		root.Get("/posts/{id:[0-9]+}", noOpHandler)
+
+		users := FindRoute(root, "/users/1")
+		posts := FindRoute(root, "/posts/1")
+
+		if assert.NotNil(t, users) && assert.NotNil(t, posts) {
+			assert.NotNil(t, users.token.re)
+			assert.Same(t, users.token.re, posts.token.re)
+		}
+	})
+
+	t.Run("it should anchor matchers to the whole segment", func(t *testing.T) {
+		token := RouteToken{value: "id", matcher: "[0-9]+", isDynamic: true}
+
+		for str, expected := range map[string]bool{
+			"123":   true,
+			"12abc": false,
+			"abc12": false,
+			"":      false,
+		} {
+			assert.Equal(t, expected, token.Match(&str), str)
+		}
+	})
+
+	t.Run("it should panic on invalid matchers", func(t *testing.T) {
+		assert.Panics(t, func() {
+			NewRouteRoot().Get("/users/{id:[0-9+}", noOpHandler)
+		})
+	})
+
+	t.Run("it should match without allocating", func(t *testing.T) {
+		root := NewRouteRoot()
+		root.Get("/users/{id:[0-9]+}/posts/{slug:[a-z-]+}", noOpHandler)
+
+		segments := []string{"/", "users", "42", "posts", "hello-world"}
+
+		allocs := testing.AllocsPerRun(100, func() {
+			_ = FindRouteBySegments(root, segments)
+		})
+		assert.Zero(t, allocs)
+	})
+}
+
 // ***************************************************************************
 // *  Benches
 // ***************************************************************************
@@ -167,3 +239,17 @@ func BenchmarkSegmentPath(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkRouteTokenMatcher(b *testing.B) {
+	root := NewRouteRoot()
+	root.Get("/users/{id:[0-9]+}/posts/{slug:[a-z-]+}", noOpHandler)
+
+	segments := []string{"/", "users", "42", "posts", "hello-world"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		FindRouteBySegments(root, segments)
+	}
+}
@@ -0,0 +1,414 @@
+package golly
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+	ErrFileNotFound        = errors.New("file not found")
+)
+
+// sniffLen is how much content http.DetectContentType looks at.
+const sniffLen = 512
+
+// ServeContent writes content as the response body, like http.ServeContent:
+// the content type comes from the extension of name or is sniffed,
+// modTime sets Last-Modified, conditional headers are answered through
+// CheckPreconditions and Range/If-Range requests get 206 responses
+// (multipart/byteranges for several ranges) or 416.
+//
+// With FormatTypeAttachment in format the response is sent with a
+// Content-Disposition of attachment named after name. Bodies are copied
+// through the response writer's ReadFrom, so *os.File content can use
+// sendfile. An ETag set before the call takes part in If-Range and the
+// conditional checks.
+//
+// Example:
+//
+//	f, _ := os.Open(report.Path)
+//	defer f.Close()
+//	wctx.ServeContent("report.csv", report.UpdatedAt, f, golly.FormatTypeAttachment)
+func (wctx *WebContext) ServeContent(name string, modTime time.Time, content io.ReadSeeker, format FormatOption) {
+	size, err := content.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = content.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		RenderError(wctx, fmt.Errorf("golly: seeking %q: %w", name, err))
+		return
+	}
+
+	h := wctx.writer.Header()
+	if h.Get("Content-Type") == "" {
+		ct, err := contentTypeOf(name, content)
+		if err != nil {
+			RenderError(wctx, fmt.Errorf("golly: sniffing %q: %w", name, err))
+			return
+		}
+		h.Set("Content-Type", ct)
+	}
+
+	if format&FormatTypeAttachment != 0 {
+		h.Set("Content-Disposition", contentDisposition("attachment", name))
+	}
+
+	if h.Get("Last-Modified") == "" && !modTime.IsZero() && !modTime.Equal(time.Unix(0, 0)) {
+		wctx.SetLastModified(modTime)
+	}
+	h.Set("Accept-Ranges", "bytes")
+
+	if wctx.CheckPreconditions() {
+		return
+	}
+
+	var ranges []httpRange
+	if rh := wctx.request.Header.Get("Range"); rh != "" && wctx.ifRangeMatches() {
+		ranges, err = parseRange(rh, size)
+		if err != nil {
+			h.Del("Content-Disposition")
+			h.Set("Content-Range", "bytes */"+strconv.FormatInt(size, 10))
+			RenderError(wctx, NewError(http.StatusRequestedRangeNotSatisfiable, ErrRangeNotSatisfiable))
+			return
+		}
+
+		// Overlapping ranges adding up to more than the content are a
+		// known amplification trick; send the whole body instead
+		if sumRanges(ranges) > size {
+			ranges = nil
+		}
+	}
+
+	head := wctx.request.Method == http.MethodHead
+
+	switch len(ranges) {
+	case 0:
+		h.Set("Content-Length", strconv.FormatInt(size, 10))
+		wctx.writer.WriteHeader(http.StatusOK)
+		if !head {
+			wctx.copyContent(content, 0, size)
+		}
+
+	case 1:
+		ra := ranges[0]
+		h.Set("Content-Range", ra.contentRange(size))
+		h.Set("Content-Length", strconv.FormatInt(ra.length, 10))
+		wctx.writer.WriteHeader(http.StatusPartialContent)
+		if !head {
+			wctx.copyContent(content, ra.start, ra.length)
+		}
+
+	default:
+		wctx.serveMultipartRanges(content, ranges, size, head)
+	}
+}
+
+// ServeFile serves name from fsys through ServeContent. Missing files and
+// directories answer 404; files that cannot seek (rare outside embed.FS
+// and os.DirFS) are buffered in memory.
+//
+// Example:
+//
+//	wctx.ServeFile(os.DirFS("/var/exports"), wctx.URLParams().Get("name"), golly.FormatTypeAttachment)
+func (wctx *WebContext) ServeFile(fsys fs.FS, name string, format FormatOption) {
+	f, info, err := openFile(fsys, name)
+	if err != nil {
+		RenderError(wctx, err)
+		return
+	}
+	defer f.Close()
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			RenderError(wctx, fmt.Errorf("golly: reading %q: %w", name, err))
+			return
+		}
+		content = bytes.NewReader(b)
+	}
+
+	wctx.ServeContent(info.Name(), info.ModTime(), content, format)
+}
+
+// openFile opens a regular file of fsys, mapping lookup failures to 404
+// *Errors. name may carry a leading slash.
+func openFile(fsys fs.FS, name string) (fs.File, fs.FileInfo, error) {
+	name = strings.TrimPrefix(name, "/")
+	if name == "" || !fs.ValidPath(name) {
+		return nil, nil, NewError(http.StatusNotFound, ErrFileNotFound)
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+			return nil, nil, NewError(http.StatusNotFound, ErrFileNotFound)
+		}
+		return nil, nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	if !info.Mode().IsRegular() {
+		f.Close()
+		return nil, nil, NewError(http.StatusNotFound, ErrFileNotFound)
+	}
+
+	return f, info, nil
+}
+
+// copyContent copies length bytes from offset through the response
+// writer, which picks up its ReadFrom fast path.
+func (wctx *WebContext) copyContent(content io.ReadSeeker, offset, length int64) {
+	if offset > 0 {
+		if _, err := content.Seek(offset, io.SeekStart); err != nil {
+			wctx.Logger().Errorf("Error seeking content: %v", err)
+			return
+		}
+	}
+
+	if _, err := io.CopyN(wctx.writer, content, length); err != nil {
+		wctx.Logger().Errorf("Error writing response: %v", err)
+	}
+}
+
+// serveMultipartRanges writes ranges as a multipart/byteranges body. The
+// Content-Length is computed up front from the part headers.
+func (wctx *WebContext) serveMultipartRanges(content io.ReadSeeker, ranges []httpRange, size int64, head bool) {
+	h := wctx.writer.Header()
+	ct := h.Get("Content-Type")
+	boundary := randomBoundary()
+
+	length, err := multipartLength(ranges, ct, size, boundary)
+	if err != nil {
+		RenderError(wctx, err)
+		return
+	}
+
+	h.Set("Content-Type", "multipart/byteranges; boundary="+boundary)
+	h.Set("Content-Length", strconv.FormatInt(length, 10))
+	wctx.writer.WriteHeader(http.StatusPartialContent)
+
+	if head {
+		return
+	}
+
+	mw := multipart.NewWriter(wctx.writer)
+	mw.SetBoundary(boundary) //nolint:errcheck // validated by multipartLength
+
+	for _, ra := range ranges {
+		part, err := mw.CreatePart(ra.mimeHeader(ct, size))
+		if err != nil {
+			wctx.Logger().Errorf("Error writing response: %v", err)
+			return
+		}
+
+		if _, err := content.Seek(ra.start, io.SeekStart); err != nil {
+			wctx.Logger().Errorf("Error seeking content: %v", err)
+			return
+		}
+		if _, err := io.CopyN(part, content, ra.length); err != nil {
+			wctx.Logger().Errorf("Error writing response: %v", err)
+			return
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		wctx.Logger().Errorf("Error writing response: %v", err)
+	}
+}
+
+// ifRangeMatches reports whether a Range header should be honoured: there
+// is no If-Range, or it names the current strong ETag or Last-Modified.
+func (wctx *WebContext) ifRangeMatches() bool {
+	ir := wctx.request.Header.Get("If-Range")
+	if ir == "" {
+		return true
+	}
+
+	h := wctx.writer.Header()
+	if strings.HasPrefix(ir, `"`) || strings.HasPrefix(ir, "W/") {
+		etag := h.Get("ETag")
+		return !isWeakETag(ir) && !isWeakETag(etag) && ir == etag
+	}
+
+	since, err := http.ParseTime(ir)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(h.Get("Last-Modified"))
+	return err == nil && modified.Equal(since)
+}
+
+// contentTypeOf derives the content type from the extension of name,
+// sniffing the first bytes of content when the extension is unknown.
+func contentTypeOf(name string, content io.ReadSeeker) (string, error) {
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		return ct, nil
+	}
+
+	var buf [sniffLen]byte
+	n, err := io.ReadFull(content, buf[:])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// contentDisposition formats a Content-Disposition value with the base of
+// name as filename, RFC 2231 encoded when it is not plain ASCII.
+func contentDisposition(disposition, name string) string {
+	filename := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if filename == "." || filename == "/" {
+		return disposition
+	}
+
+	if v := mime.FormatMediaType(disposition, map[string]string{"filename": filename}); v != "" {
+		return v
+	}
+	return disposition
+}
+
+// httpRange is a resolved byte range of the content.
+type httpRange struct {
+	start, length int64
+}
+
+func (r httpRange) contentRange(size int64) string {
+	return "bytes " + strconv.FormatInt(r.start, 10) + "-" + strconv.FormatInt(r.start+r.length-1, 10) + "/" + strconv.FormatInt(size, 10)
+}
+
+func (r httpRange) mimeHeader(contentType string, size int64) textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		"Content-Range": {r.contentRange(size)},
+		"Content-Type":  {contentType},
+	}
+}
+
+// parseRange parses a "bytes=" Range header against a content of size
+// bytes. Ranges starting past the end are dropped; an error means no range
+// can be satisfied (416). Other units or a malformed header yield no
+// ranges, so the whole content is served.
+func parseRange(s string, size int64) ([]httpRange, error) {
+	spec, ok := strings.CutPrefix(s, "bytes=")
+	if !ok {
+		return nil, nil
+	}
+
+	var ranges []httpRange
+	for part := range strings.SplitSeq(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		first, last, ok := strings.Cut(part, "-")
+		if !ok {
+			return nil, nil
+		}
+		first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+
+		var r httpRange
+		if first == "" {
+			// Suffix range: the last N bytes
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, nil
+			}
+			if n == 0 {
+				continue
+			}
+			n = min(n, size)
+			r = httpRange{start: size - n, length: n}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, nil
+			}
+			if start >= size {
+				continue
+			}
+
+			end := size - 1
+			if last != "" {
+				e, err := strconv.ParseInt(last, 10, 64)
+				if err != nil || e < start {
+					return nil, nil
+				}
+				end = min(e, size-1)
+			}
+			r = httpRange{start: start, length: end - start + 1}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		return nil, ErrRangeNotSatisfiable
+	}
+	return ranges, nil
+}
+
+func sumRanges(ranges []httpRange) (n int64) {
+	for _, r := range ranges {
+		n += r.length
+	}
+	return n
+}
+
+// multipartLength returns the exact size of the multipart/byteranges body
+// for ranges, by writing the part headers to a counter.
+func multipartLength(ranges []httpRange, contentType string, size int64, boundary string) (int64, error) {
+	var cw countingWriter
+
+	mw := multipart.NewWriter(&cw)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return 0, err
+	}
+
+	for _, ra := range ranges {
+		if _, err := mw.CreatePart(ra.mimeHeader(contentType, size)); err != nil {
+			return 0, err
+		}
+		cw += countingWriter(ra.length)
+	}
+
+	if err := mw.Close(); err != nil {
+		return 0, err
+	}
+	return int64(cw), nil
+}
+
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
+func randomBoundary() string {
+	var buf [16]byte
+	rand.Read(buf[:]) //nolint:errcheck // crypto/rand never fails
+	return hex.EncodeToString(buf[:])
+}
@@ -0,0 +1,209 @@
+package golly
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const serveBody = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+var serveModTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func serveContent(method string, headers map[string]string, setup func(*WebContext), name string, format FormatOption) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	wctx := NewTestWebContext(testRequest(method, "/download", nil, headers), rec)
+	if setup != nil {
+		setup(wctx)
+	}
+
+	wctx.ServeContent(name, serveModTime, strings.NewReader(serveBody), format)
+	return rec
+}
+
+func TestServeContent(t *testing.T) {
+	t.Run("it should serve the whole content", func(t *testing.T) {
+		rec := serveContent(http.MethodGet, nil, nil, "data.txt", 0)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, serveBody, rec.Body.String())
+		assert.Equal(t, "36", rec.Header().Get("Content-Length"))
+		assert.Equal(t, "bytes", rec.Header().Get("Accept-Ranges"))
+		assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, serveModTime.Format(http.TimeFormat), rec.Header().Get("Last-Modified"))
+		assert.Empty(t, rec.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("it should sniff unknown extensions", func(t *testing.T) {
+		rec := serveContent(http.MethodGet, nil, nil, "data", 0)
+
+		assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, serveBody, rec.Body.String())
+	})
+
+	t.Run("it should set Content-Disposition for attachments", func(t *testing.T) {
+		rec := serveContent(http.MethodGet, nil, nil, "exports/résumé 2024.txt", FormatTypeAttachment)
+
+		disposition, params, err := mime.ParseMediaType(rec.Header().Get("Content-Disposition"))
+		require.NoError(t, err)
+		assert.Equal(t, "attachment", disposition)
+		assert.Equal(t, "résumé 2024.txt", params["filename"])
+	})
+
+	t.Run("it should serve a single range with 206", func(t *testing.T) {
+		tests := map[string]string{
+			"bytes=0-4":   "01234",
+			"bytes=30-":   "uvwxyz",
+			"bytes=-3":    "xyz",
+			"bytes=34-99": "yz",
+		}
+
+		for header, want := range tests {
+			rec := serveContent(http.MethodGet, map[string]string{"Range": header}, nil, "data.txt", 0)
+
+			assert.Equal(t, http.StatusPartialContent, rec.Code, header)
+			assert.Equal(t, want, rec.Body.String(), header)
+		}
+
+		rec := serveContent(http.MethodGet, map[string]string{"Range": "bytes=10-12"}, nil, "data.txt", 0)
+		assert.Equal(t, "bytes 10-12/36", rec.Header().Get("Content-Range"))
+		assert.Equal(t, "3", rec.Header().Get("Content-Length"))
+	})
+
+	t.Run("it should serve several ranges as multipart/byteranges", func(t *testing.T) {
+		rec := serveContent(http.MethodGet, map[string]string{"Range": "bytes=0-1, 10-11"}, nil, "data.txt", 0)
+		require.Equal(t, http.StatusPartialContent, rec.Code)
+
+		mediaType, params, err := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/byteranges", mediaType)
+		assert.Equal(t, strconv.Itoa(rec.Body.Len()), rec.Header().Get("Content-Length"))
+
+		mr := multipart.NewReader(rec.Body, params["boundary"])
+
+		var parts []string
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+
+			b, _ := io.ReadAll(part)
+			parts = append(parts, part.Header.Get("Content-Range")+"="+string(b))
+			assert.Equal(t, "text/plain; charset=utf-8", part.Header.Get("Content-Type"))
+		}
+
+		assert.Equal(t, []string{"bytes 0-1/36=01", "bytes 10-11/36=ab"}, parts)
+	})
+
+	t.Run("it should answer unsatisfiable ranges with 416", func(t *testing.T) {
+		rec := serveContent(http.MethodGet, map[string]string{"Range": "bytes=100-200"}, nil, "data.txt", FormatTypeAttachment)
+
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, rec.Code)
+		assert.Equal(t, "bytes */36", rec.Header().Get("Content-Range"))
+		assert.Empty(t, rec.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("it should ignore malformed and amplifying ranges", func(t *testing.T) {
+		for _, header := range []string{"items=0-1", "bytes=5-1", "bytes=0-35,0-35"} {
+			rec := serveContent(http.MethodGet, map[string]string{"Range": header}, nil, "data.txt", 0)
+
+			assert.Equal(t, http.StatusOK, rec.Code, header)
+			assert.Equal(t, serveBody, rec.Body.String(), header)
+		}
+	})
+
+	t.Run("it should honour If-Range", func(t *testing.T) {
+		withETag := func(wctx *WebContext) { wctx.SetETag("v1", false) }
+
+		rec := serveContent(http.MethodGet, map[string]string{"Range": "bytes=0-1", "If-Range": `"v1"`}, withETag, "data.txt", 0)
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+
+		rec = serveContent(http.MethodGet, map[string]string{"Range": "bytes=0-1", "If-Range": `"v0"`}, withETag, "data.txt", 0)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, serveBody, rec.Body.String())
+
+		rec = serveContent(http.MethodGet, map[string]string{"Range": "bytes=0-1", "If-Range": serveModTime.Format(http.TimeFormat)}, nil, "data.txt", 0)
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+
+		rec = serveContent(http.MethodGet, map[string]string{"Range": "bytes=0-1", "If-Range": serveModTime.Add(-time.Hour).Format(http.TimeFormat)}, nil, "data.txt", 0)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("it should answer conditional requests", func(t *testing.T) {
+		rec := serveContent(http.MethodGet, map[string]string{"If-Modified-Since": serveModTime.Format(http.TimeFormat)}, nil, "data.txt", 0)
+
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Zero(t, rec.Body.Len())
+	})
+
+	t.Run("it should send headers only for HEAD", func(t *testing.T) {
+		rec := serveContent(http.MethodHead, nil, nil, "data.txt", 0)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "36", rec.Header().Get("Content-Length"))
+		assert.Zero(t, rec.Body.Len())
+	})
+
+	t.Run("it should copy through ReadFrom", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/download", nil)
+		rf := &countingReaderFrom{ResponseWriter: httptest.NewRecorder()}
+
+		wctx := NewTestWebContext(req, rf)
+		wctx.ServeContent("data.txt", serveModTime, strings.NewReader(serveBody), 0)
+
+		assert.Equal(t, int64(len(serveBody)), rf.n)
+		assert.Equal(t, len(serveBody), wctx.Response().(WrapResponseWriter).BytesWritten())
+	})
+}
+
+func TestServeFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/guide.html": {Data: []byte("<h1>guide</h1>"), ModTime: serveModTime},
+	}
+
+	serve := func(name string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		NewTestWebContext(req, rec).ServeFile(fsys, name, 0)
+		return rec
+	}
+
+	t.Run("it should serve a file from the fs", func(t *testing.T) {
+		rec := serve("/docs/guide.html")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "<h1>guide</h1>", rec.Body.String())
+		assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, serveModTime.Format(http.TimeFormat), rec.Header().Get("Last-Modified"))
+	})
+
+	t.Run("it should answer 404 for missing files, directories and invalid paths", func(t *testing.T) {
+		for _, name := range []string{"docs/missing.html", "docs", "../etc/passwd", ""} {
+			assert.Equal(t, http.StatusNotFound, serve(name).Code, name)
+		}
+	})
+}
+
+// countingReaderFrom records bytes that went through ReadFrom.
+type countingReaderFrom struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (c *countingReaderFrom) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.Copy(c.ResponseWriter, r)
+	c.n += n
+	return n, err
+}
@@ -0,0 +1,207 @@
+package golly
+
+import (
+	"bytes"
+	"context"
+	"iter"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// SSEEvent is a single Server-Sent Event. Data is written as is when it is
+// a string or []byte and JSON encoded otherwise; multi-line data is split
+// into one data field per line.
+type SSEEvent struct {
+	ID    string
+	Event string
+	Retry time.Duration
+	Data  any
+}
+
+// LastEventID returns the Last-Event-ID header a reconnecting EventSource
+// sends, so SSE handlers can resume after the last delivered event.
+func (wctx *WebContext) LastEventID() string {
+	return wctx.request.Header.Get("Last-Event-ID")
+}
+
+// ChanSeq adapts a channel to an iterator that ends when the channel is
+// closed or ctx is done, so stream helpers never block on an abandoned
+// channel after the client went away.
+//
+// Example:
+//
+//	golly.StreamSSE(wctx, golly.ChanSeq(wctx.Context(), events))
+func ChanSeq[T any](ctx context.Context, ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-ch:
+				if !ok || !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// StreamNDJSON writes each value from seq as a line of JSON
+// (application/x-ndjson), flushing after every value. It returns when seq
+// ends, a write fails, or the request context is done (returning its
+// error).
+func StreamNDJSON[T any](wctx *WebContext, seq iter.Seq[T]) error {
+	enc := json.NewEncoder(wctx.writer)
+
+	return stream(wctx, "application/x-ndjson", seq, func(v T) error {
+		return enc.Encode(v) // Encode terminates each value with '\n'
+	})
+}
+
+// StreamJSONArray writes the values from seq as a single JSON array,
+// flushing after every element, so large exports never sit in memory.
+// The closing bracket is only written when seq ends normally.
+func StreamJSONArray[T any](wctx *WebContext, seq iter.Seq[T]) error {
+	first := true
+
+	err := stream(wctx, "application/json", seq, func(v T) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		sep := byte(',')
+		if first {
+			sep, first = '[', false
+		}
+
+		if _, err := wctx.writer.Write([]byte{sep}); err != nil {
+			return err
+		}
+		_, err = wctx.writer.Write(b)
+		return err
+	})
+	if err != nil || wctx.request.Method == http.MethodHead {
+		return err
+	}
+
+	closing := "]"
+	if first {
+		closing = "[]"
+	}
+	_, err = wctx.writer.Write(unsafeBytes(closing))
+	wctx.flush()
+	return err
+}
+
+// StreamSSE writes each event from seq as a Server-Sent Event
+// (text/event-stream), flushing after every event.
+//
+// Example:
+//
+//	app.Routes().Get("/feed", func(wctx *golly.WebContext) {
+//	    since := wctx.LastEventID()
+//	    golly.StreamSSE(wctx, feed.Since(wctx.Context(), since))
+//	})
+func StreamSSE(wctx *WebContext, seq iter.Seq[SSEEvent]) error {
+	h := wctx.writer.Header()
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // keep reverse proxies from buffering
+
+	var buf bytes.Buffer
+	return stream(wctx, "text/event-stream", seq, func(ev SSEEvent) error {
+		buf.Reset()
+		if err := writeSSEEvent(&buf, ev); err != nil {
+			return err
+		}
+		_, err := wctx.writer.Write(buf.Bytes())
+		return err
+	})
+}
+
+// writeSSEEvent encodes ev in the text/event-stream format.
+func writeSSEEvent(buf *bytes.Buffer, ev SSEEvent) error {
+	if ev.ID != "" {
+		writeSSEField(buf, "id", ev.ID)
+	}
+	if ev.Event != "" {
+		writeSSEField(buf, "event", ev.Event)
+	}
+	if ev.Retry > 0 {
+		writeSSEField(buf, "retry", strconv.FormatInt(ev.Retry.Milliseconds(), 10))
+	}
+
+	var data []byte
+	switch d := ev.Data.(type) {
+	case nil:
+	case string:
+		data = unsafeBytes(d)
+	case []byte:
+		data = d
+	default:
+		b, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		data = b
+	}
+
+	if data != nil {
+		for line := range bytes.Lines(data) {
+			writeSSEField(buf, "data", string(bytes.TrimRight(line, "\r\n")))
+		}
+	}
+
+	buf.WriteByte('\n')
+	return nil
+}
+
+// writeSSEField writes "name: value\n", dropping line breaks from value
+// since they would end the field early.
+func writeSSEField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c != '\n' && c != '\r' {
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte('\n')
+}
+
+// stream sets the content type, commits the headers and calls write for
+// each value of seq, flushing after each one. It stops early when the
+// request context is done.
+func stream[T any](wctx *WebContext, contentType string, seq iter.Seq[T], write func(T) error) error {
+	wctx.writer.Header().Set("Content-Type", contentType)
+	wctx.writer.WriteHeader(http.StatusOK)
+	wctx.flush()
+
+	if wctx.request.Method == http.MethodHead {
+		return nil
+	}
+
+	ctx := wctx.ctx
+	for v := range seq {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := write(v); err != nil {
+			return err
+		}
+		wctx.flush()
+	}
+
+	return ctx.Err()
+}
+
+// flush pushes buffered response bytes to the client when supported.
+func (wctx *WebContext) flush() {
+	if f, ok := wctx.writer.(http.Flusher); ok {
+		f.Flush()
+	}
+}
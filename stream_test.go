@@ -0,0 +1,143 @@
+package golly
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type streamItem struct {
+	ID int `json:"id"`
+}
+
+func streamContext(method string, header http.Header) (*WebContext, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, "/", nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	w := httptest.NewRecorder()
+	return NewTestWebContext(req, w), w
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	wctx, w := streamContext(http.MethodGet, nil)
+
+	err := StreamNDJSON(wctx, slices.Values([]streamItem{{1}, {2}, {3}}))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Equal(t, "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n", w.Body.String())
+	assert.True(t, w.Flushed)
+}
+
+func TestStreamJSONArray(t *testing.T) {
+	t.Run("it should write a JSON array", func(t *testing.T) {
+		wctx, w := streamContext(http.MethodGet, nil)
+
+		require.NoError(t, StreamJSONArray(wctx, slices.Values([]streamItem{{1}, {2}})))
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `[{"id":1},{"id":2}]`, w.Body.String())
+	})
+
+	t.Run("it should write an empty array", func(t *testing.T) {
+		wctx, w := streamContext(http.MethodGet, nil)
+
+		require.NoError(t, StreamJSONArray(wctx, slices.Values([]streamItem{})))
+		assert.Equal(t, "[]", w.Body.String())
+	})
+
+	t.Run("it should only send headers for HEAD", func(t *testing.T) {
+		wctx, w := streamContext(http.MethodHead, nil)
+
+		require.NoError(t, StreamJSONArray(wctx, slices.Values([]streamItem{{1}})))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+}
+
+func TestStreamSSE(t *testing.T) {
+	wctx, w := streamContext(http.MethodGet, http.Header{"Last-Event-Id": {"41"}})
+	assert.Equal(t, "41", wctx.LastEventID())
+
+	events := []SSEEvent{
+		{ID: "42", Event: "order", Retry: 3 * time.Second, Data: streamItem{42}},
+		{Data: "line one\nline two"},
+		{ID: "bad\nid", Data: []byte("raw")},
+	}
+
+	require.NoError(t, StreamSSE(wctx, slices.Values(events)))
+
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "id: 42\nevent: order\nretry: 3000\ndata: {\"id\":42}\n\n"+
+		"data: line one\ndata: line two\n\n"+
+		"id: badid\ndata: raw\n\n", w.Body.String())
+}
+
+func TestStreamCancellation(t *testing.T) {
+	t.Run("it should stop a channel stream when the request is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		wctx := NewWebContext(ctx, req, w)
+
+		ch := make(chan streamItem)
+		go func() {
+			ch <- streamItem{1}
+			cancel()
+		}()
+
+		done := make(chan error)
+		go func() { done <- StreamNDJSON(wctx, ChanSeq(wctx.Context(), ch)) }()
+
+		select {
+		case err := <-done:
+			assert.True(t, errors.Is(err, context.Canceled), "got %v", err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("stream did not stop after cancellation")
+		}
+	})
+
+	t.Run("it should stop an iterator between values", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		wctx := NewWebContext(ctx, req, w)
+
+		seq := func(yield func(int) bool) {
+			for i := 0; ; i++ {
+				if i == 2 {
+					cancel()
+					<-wctx.Context().Done()
+				}
+				if !yield(i) {
+					return
+				}
+			}
+		}
+
+		err := StreamJSONArray(wctx, seq)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, "[0,1", w.Body.String())
+	})
+
+	t.Run("it should end with the channel", func(t *testing.T) {
+		ch := make(chan int, 2)
+		ch <- 1
+		ch <- 2
+		close(ch)
+
+		assert.Equal(t, []int{1, 2}, slices.Collect(ChanSeq(context.Background(), ch)))
+	})
+}
@@ -0,0 +1,124 @@
+package golly
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+)
+
+// TypedHandlerFunc is a handler that receives its decoded input and returns
+// the value to render, or an error.
+type TypedHandlerFunc[In, Out any] func(ctx *Context, in In) (Out, error)
+
+// Typed adapts fn into a HandlerFunc. The input is bound with
+// WebContext.Bind when In is a struct (or pointer to one) and decoded with
+// WebContext.Marshal otherwise. A successful result is rendered as JSON
+// through Render; errors, including bind failures, go through RenderError so
+// HTTPError statuses and messages reach the client.
+//
+// Use Handle to register fn with its RouteDoc attached.
+func Typed[In, Out any](fn TypedHandlerFunc[In, Out]) HandlerFunc {
+	decode := typedDecoder[In]()
+
+	return func(wctx *WebContext) {
+		in, err := decode(wctx)
+		if err != nil {
+			RenderError(wctx, err)
+			return
+		}
+
+		out, err := fn(wctx.ctx, in)
+		if err != nil {
+			RenderError(wctx, err)
+			return
+		}
+
+		Render(wctx, FormatTypeJSON, out)
+	}
+}
+
+// Handle registers fn on re for the given methods through Typed, with a
+// RouteDoc describing In and Out. A RouteDoc passed in docs keeps its
+// description and name and gains the Input/Output it does not already set.
+//
+// Example:
+//
+//	golly.Handle(r, golly.POST, "/orders", func(ctx *golly.Context, in CreateOrder) (Order, error) {
+//	    return orders.Create(ctx, in)
+//	}, golly.Describe("create an order"))
+func Handle[In, Out any](re *Route, meth methodType, path string, fn TypedHandlerFunc[In, Out], docs ...*RouteDoc) *Route {
+	var doc *RouteDoc
+	if len(docs) > 0 {
+		doc = docs[0]
+	}
+
+	return re.Add(path, Typed(fn), meth, typedDoc[In, Out](doc))
+}
+
+// typedDoc returns a copy of doc with the Input and Output filled from the
+// handler types, so a doc shared by several registrations stays untouched.
+func typedDoc[In, Out any](doc *RouteDoc) *RouteDoc {
+	if doc == nil {
+		doc = &RouteDoc{}
+	} else {
+		d := *doc
+		d.params = slices.Clip(d.params) // appends must not share the backing array
+		doc = &d
+	}
+
+	if doc.input == nil {
+		doc.Input(typeZero[In]())
+	}
+	if doc.output == nil {
+		doc.Output(typeZero[Out]())
+	}
+
+	return doc
+}
+
+// typeZero returns a zero T boxed in an interface, or nil for interface
+// types, whose zero value carries no type to reflect on.
+func typeZero[T any]() any {
+	if reflect.TypeFor[T]().Kind() == reflect.Interface {
+		return nil
+	}
+	var v T
+	return v
+}
+
+// typedDecoder picks the decode strategy for In once, at adaptation time.
+func typedDecoder[In any]() func(*WebContext) (In, error) {
+	t := reflect.TypeFor[In]()
+
+	switch {
+	case t.Kind() == reflect.Struct:
+		return func(wctx *WebContext) (In, error) {
+			var in In
+			err := wctx.Bind(&in)
+			return in, err
+		}
+
+	case t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct:
+		return func(wctx *WebContext) (In, error) {
+			in := reflect.New(t.Elem()).Interface().(In)
+			err := wctx.Bind(in)
+			return in, err
+		}
+	}
+
+	return func(wctx *WebContext) (In, error) {
+		var in In
+		body, err := wctx.Body()
+		if err != nil || len(body) == 0 {
+			return in, err
+		}
+
+		if err := wctx.Marshal(&in); err != nil {
+			if _, ok := err.(HTTPError); ok {
+				return in, err
+			}
+			return in, NewError(http.StatusBadRequest, err)
+		}
+		return in, nil
+	}
+}
@@ -0,0 +1,172 @@
+package golly
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedCreateOrder struct {
+	Tenant string `header:"X-Tenant"`
+	Name   string `json:"name"`
+	Qty    int    `json:"qty"`
+}
+
+type typedOrder struct {
+	ID     int    `json:"id"`
+	Tenant string `json:"tenant"`
+	Name   string `json:"name"`
+}
+
+type teapotError struct{}
+
+func (teapotError) Error() string   { return "short and stout" }
+func (teapotError) Status() int     { return http.StatusTeapot }
+func (teapotError) Message() string { return "i am a teapot" }
+
+func serveTyped(app *Application, method, target, body string) *httptest.ResponseRecorder {
+	return serveRequest(app, testRequest(method, target, strings.NewReader(body), map[string]string{"X-Tenant": "acme"}))
+}
+
+func TestTypedHandler(t *testing.T) {
+	app := NewApplication(Options{})
+
+	Handle(app.routes, POST, "/orders", func(ctx *Context, in typedCreateOrder) (typedOrder, error) {
+		switch in.Name {
+		case "":
+			return typedOrder{}, NewError(http.StatusUnprocessableEntity, errors.New("name is required"))
+		case "teapot":
+			return typedOrder{}, teapotError{}
+		case "boom":
+			return typedOrder{}, errors.New("db password leaked")
+		}
+		return typedOrder{ID: in.Qty, Tenant: in.Tenant, Name: in.Name}, nil
+	}, Describe("create order"))
+
+	Handle(app.routes, PUT, "/orders/tags", func(ctx *Context, in []string) (int, error) {
+		return len(in), nil
+	})
+
+	Handle(app.routes, GET, "/orders/{id:int}", func(ctx *Context, in *struct {
+		ID int `path:"id"`
+	}) (*typedOrder, error) {
+		return &typedOrder{ID: in.ID}, nil
+	})
+
+	t.Run("it should bind input and render output", func(t *testing.T) {
+		w := serveTyped(app, http.MethodPost, "/orders", `{"name":"desk","qty":3}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"id":3,"tenant":"acme","name":"desk"}`, w.Body.String())
+	})
+
+	t.Run("it should bind pointer inputs", func(t *testing.T) {
+		w := serveTyped(app, http.MethodGet, "/orders/9", "")
+		assert.JSONEq(t, `{"id":9,"tenant":"","name":""}`, w.Body.String())
+	})
+
+	t.Run("it should decode non-struct inputs", func(t *testing.T) {
+		w := serveTyped(app, http.MethodPut, "/orders/tags", `["a","b"]`)
+		assert.Equal(t, "2", w.Body.String())
+
+		w = serveTyped(app, http.MethodPut, "/orders/tags", `{`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		message string
+	}{
+		{name: "golly error", body: `{"name":""}`, status: http.StatusUnprocessableEntity, message: "name is required"},
+		{name: "http error", body: `{"name":"teapot"}`, status: http.StatusTeapot, message: "i am a teapot"},
+		{name: "plain error", body: `{"name":"boom"}`, status: http.StatusInternalServerError, message: "Internal Server Error"},
+		{name: "bind error", body: `{"qty":"x"}`, status: http.StatusBadRequest, message: "invalid request parameters"},
+	}
+
+	for _, tt := range tests {
+		t.Run("it should map "+tt.name, func(t *testing.T) {
+			w := serveTyped(app, http.MethodPost, "/orders", tt.body)
+
+			assert.Equal(t, tt.status, w.Code)
+
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.message, body["message"])
+			assert.NotContains(t, w.Body.String(), "leaked")
+		})
+	}
+
+	t.Run("it should attach a RouteDoc", func(t *testing.T) {
+		var create, show *RouteDoc
+		for _, info := range app.routes.Table() {
+			switch info.Method + " " + info.Path {
+			case "POST /orders":
+				create = info.Doc
+			case "GET /orders/{id:int}":
+				show = info.Doc
+			}
+		}
+
+		require.NotNil(t, create)
+		assert.Equal(t, "create order", create.Description())
+
+		var params []string
+		for _, p := range create.Params() {
+			params = append(params, string(p.Source)+":"+p.Name)
+		}
+		assert.Equal(t, []string{
+			"header:X-Tenant", "input:name", "input:qty",
+			"output:id", "output:tenant", "output:name",
+		}, params)
+
+		require.NotNil(t, show)
+		op := app.routes.OpenAPI(OpenAPIInfo{}).Paths["/orders/{id}"]["get"]
+		require.NotNil(t, op)
+		assert.Nil(t, op.RequestBody, "path-only input has no body")
+		assert.Equal(t, "#/components/schemas/typedOrder", op.Responses["200"].Content["application/json"].Schema.Ref)
+	})
+
+	t.Run("it should not fill a shared RouteDoc", func(t *testing.T) {
+		shared := Describe("shared")
+		a := NewApplication(Options{})
+
+		Handle(a.routes, POST, "/a", func(ctx *Context, in typedCreateOrder) (typedOrder, error) { return typedOrder{}, nil }, shared)
+		Handle(a.routes, POST, "/b", func(ctx *Context, in []string) (int, error) { return 0, nil }, shared)
+
+		assert.Empty(t, shared.Params())
+
+		docs := map[string]*RouteDoc{}
+		for _, info := range a.routes.Table() {
+			docs[info.Path] = info.Doc
+		}
+		require.NotNil(t, docs["/a"])
+		require.NotNil(t, docs["/b"])
+		assert.Equal(t, "shared", docs["/b"].Description())
+		assert.Equal(t, reflect.TypeFor[typedCreateOrder](), docs["/a"].input)
+		assert.Equal(t, reflect.TypeFor[[]string](), docs["/b"].input)
+	})
+}
+
+func TestAsError(t *testing.T) {
+	gerr := NewError(http.StatusConflict, errors.New("taken"))
+	assert.Same(t, gerr, AsError(gerr))
+
+	herr := AsError(teapotError{})
+	assert.Equal(t, http.StatusTeapot, herr.Status())
+	assert.Equal(t, "i am a teapot", herr.Error())
+	assert.ErrorIs(t, herr, teapotError{})
+
+	plain := AsError(errors.New("secret"))
+	assert.Equal(t, http.StatusInternalServerError, plain.Status())
+	assert.Equal(t, "Internal Server Error", plain.Error())
+}
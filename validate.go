@@ -0,0 +1,381 @@
+package golly
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var ErrValidationFailed = errors.New("validation failed")
+
+// validationRule is a single parsed rule from a validate tag.
+type validationRule struct {
+	name  string
+	param string
+
+	num   float64        // min, max, len
+	re    *regexp.Regexp // regex
+	oneOf []string       // oneof
+}
+
+func (r validationRule) String() string {
+	if r.param == "" {
+		return r.name
+	}
+	return r.name + "=" + r.param
+}
+
+// fieldRules are the rules for a value, plus the rules for its elements
+// when the tag contains dive.
+type fieldRules struct {
+	rules []validationRule
+	elem  *fieldRules
+}
+
+func (fr *fieldRules) required() bool {
+	return fr.has("required")
+}
+
+func (fr *fieldRules) has(name string) bool {
+	for _, r := range fr.rules {
+		if r.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validatedField pairs a struct field with its parsed rules.
+type validatedField struct {
+	structField
+	rules fieldRules
+}
+
+// validatedType is a cached validatedFields result.
+type validatedType struct {
+	fields []validatedField
+	err    error
+}
+
+var validatedFieldsCache sync.Map // reflect.Type -> validatedType
+
+// Validate checks v (a struct or pointer to one) against the rules declared
+// in validate tags and returns a 422 *Error listing every violation in its
+// "fields" extension, or nil.
+//
+// Rules are comma separated:
+//
+//	required      non-zero value; non-empty string, slice or map
+//	omitempty     skip the other rules when the value is empty
+//	min=N, max=N  numeric bounds, or length bounds for strings, slices and maps
+//	len=N         exact length (or value for numbers)
+//	oneof=a b c   value must be one of the space separated options
+//	email         RFC 5322 address without display name
+//	regex=PATTERN string must match PATTERN; must be the last rule
+//	dive          rules after dive apply to each slice/array/map element
+//
+// required:"true" is equivalent to the required rule. Rules also apply to
+// zero values, so min=1 rejects 0 and oneof rejects ""; tag optional fields
+// omitempty to skip them when empty. Nil pointers count as absent and skip
+// every rule but required. Nested structs are always validated. Unknown
+// rules are ignored so tags shared with other validators keep working.
+// RouteDoc and OpenAPI report the same rules.
+//
+// A malformed tag panics when a RouteDoc reflects the type at registration;
+// Validate itself reports it as a 500 *Error.
+//
+// Example:
+//
+//	type CreateUser struct {
+//	    Email string   `json:"email" validate:"required,email"`
+//	    Age   int      `json:"age" validate:"min=18,max=130"`
+//	    Role  string   `json:"role" validate:"omitempty,oneof=admin member"`
+//	    Tags  []string `json:"tags" validate:"max=5,dive,min=2"`
+//	}
+func Validate(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("golly: Validate requires a struct, got %T", v)
+	}
+
+	var errs []FieldError
+	if err := validateStruct(rv, "", ParamSourceInput, &errs); err != nil {
+		return NewError(http.StatusInternalServerError, err)
+	}
+
+	if len(errs) > 0 {
+		return NewError(http.StatusUnprocessableEntity, ErrValidationFailed, map[string]any{"fields": errs})
+	}
+	return nil
+}
+
+func validateStruct(rv reflect.Value, prefix string, source ParamSource, errs *[]FieldError) error {
+	fields, err := validatedFields(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		name, src := f.name, f.source
+		if prefix != "" {
+			name, src = prefix+"."+f.name, source
+		}
+
+		if err := validateValue(rv.FieldByIndex(f.index), &f.rules, name, src, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateValue(v reflect.Value, fr *fieldRules, name string, source ParamSource, errs *[]FieldError) error {
+	if isEmptyValue(v) {
+		if fr.required() {
+			*errs = append(*errs, FieldError{Field: name, Source: source, Message: "is required"})
+			return nil
+		}
+		if fr.has("omitempty") {
+			return nil
+		}
+	}
+
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	for _, r := range fr.rules {
+		if msg := r.check(v); msg != "" {
+			*errs = append(*errs, FieldError{Field: name, Source: source, Message: msg})
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return validateStruct(v, name, source, errs)
+
+	case reflect.Slice, reflect.Array:
+		if fr.elem == nil {
+			return nil
+		}
+		for i := range v.Len() {
+			if err := validateValue(v.Index(i), fr.elem, name+"["+strconv.Itoa(i)+"]", source, errs); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		if fr.elem == nil {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := validateValue(iter.Value(), fr.elem, fmt.Sprintf("%s[%v]", name, iter.Key()), source, errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isEmptyValue reports whether v counts as missing for the required rule.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// check returns a violation message, or "" when v satisfies the rule.
+func (r validationRule) check(v reflect.Value) string {
+	switch r.name {
+	case "min":
+		if n, isLen := measure(v); n < r.num {
+			return boundMessage("at least", r.param, isLen)
+		}
+	case "max":
+		if n, isLen := measure(v); n > r.num {
+			return boundMessage("at most", r.param, isLen)
+		}
+	case "len":
+		if n, isLen := measure(v); n != r.num {
+			return boundMessage("exactly", r.param, isLen)
+		}
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		for _, opt := range r.oneOf {
+			if s == opt {
+				return ""
+			}
+		}
+		return "must be one of: " + strings.Join(r.oneOf, ", ")
+	case "email":
+		if v.Kind() != reflect.String || !isEmail(v.String()) {
+			return "must be a valid email address"
+		}
+	case "regex":
+		if v.Kind() != reflect.String || !r.re.MatchString(v.String()) {
+			return "must match " + r.param
+		}
+	}
+	return ""
+}
+
+// measure returns the numeric value of v, or its length for strings,
+// slices, arrays and maps.
+func measure(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false
+	}
+	return 0, false
+}
+
+func boundMessage(bound, param string, isLen bool) string {
+	if isLen {
+		return "length must be " + bound + " " + param
+	}
+	return "must be " + bound + " " + param
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// validatedFields returns the fields of t with their parsed rules, or the
+// error of the first malformed validate tag.
+func validatedFields(t reflect.Type) ([]validatedField, error) {
+	if v, ok := validatedFieldsCache.Load(t); ok {
+		vt := v.(validatedType)
+		return vt.fields, vt.err
+	}
+
+	var vt validatedType
+
+	fields := structFields(t, ParamSourceInput)
+	vt.fields = make([]validatedField, 0, len(fields))
+	for _, f := range fields {
+		fr, err := parseFieldRules(f.field)
+		if err != nil {
+			vt = validatedType{err: fmt.Errorf("golly: %s: %w", t, err)}
+			break
+		}
+		vt.fields = append(vt.fields, validatedField{structField: f, rules: fr})
+	}
+
+	v, _ := validatedFieldsCache.LoadOrStore(t, vt)
+	vt = v.(validatedType)
+	return vt.fields, vt.err
+}
+
+// rulesOf is parseFieldRules for registration time callers (RouteDoc and
+// OpenAPI): malformed tags are programming errors and panic, like invalid
+// route patterns do.
+func rulesOf(field reflect.StructField) fieldRules {
+	fr, err := parseFieldRules(field)
+	if err != nil {
+		panic("golly: " + err.Error())
+	}
+	return fr
+}
+
+// parseFieldRules parses the validate tag of field, folding in
+// required:"true".
+func parseFieldRules(field reflect.StructField) (fieldRules, error) {
+	fr, err := parseRules(field.Tag.Get("validate"))
+	if err != nil {
+		return fr, fmt.Errorf("field %s: %w", field.Name, err)
+	}
+
+	if field.Tag.Get("required") == "true" && !fr.required() {
+		fr.rules = append(fr.rules, validationRule{name: "required"})
+	}
+	return fr, nil
+}
+
+func parseRules(tag string) (fieldRules, error) {
+	var fr fieldRules
+
+	for tag != "" {
+		var part string
+
+		// A regex may contain commas, so it consumes the rest of the tag
+		if strings.HasPrefix(strings.TrimSpace(tag), "regex=") {
+			part, tag = strings.TrimSpace(tag), ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+			part = strings.TrimSpace(part)
+		}
+
+		if part == "" {
+			continue
+		}
+
+		if part == "dive" {
+			elem, err := parseRules(tag)
+			if err != nil {
+				return fr, err
+			}
+			fr.elem = &elem
+			return fr, nil
+		}
+
+		name, param, _ := strings.Cut(part, "=")
+		r := validationRule{name: name, param: param}
+
+		switch name {
+		case "required", "omitempty", "email":
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return fr, fmt.Errorf("invalid %s rule %q", name, part)
+			}
+			r.num = n
+		case "oneof":
+			r.oneOf = strings.Fields(param)
+			if len(r.oneOf) == 0 {
+				return fr, fmt.Errorf("empty oneof rule")
+			}
+		case "regex":
+			re, err := regexp.Compile(param)
+			if err != nil {
+				return fr, fmt.Errorf("invalid regex rule: %w", err)
+			}
+			r.re = re
+		default:
+			continue // not ours, e.g. a rule for another validator
+		}
+
+		fr.rules = append(fr.rules, r)
+	}
+
+	return fr, nil
+}
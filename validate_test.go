@@ -0,0 +1,244 @@
+package golly
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validateAddress struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"regex=^[0-9]{5}(-[0-9]{4})?$"`
+}
+
+type validateLine struct {
+	SKU      string `json:"sku" validate:"required,len=8"`
+	Quantity int    `json:"quantity" validate:"min=1,max=99"`
+}
+
+type validateOrder struct {
+	Email    string            `json:"email" validate:"required,email"`
+	Name     string            `json:"name" required:"true" validate:"min=2,max=5"`
+	Status   string            `json:"status" validate:"omitempty,oneof=open closed"`
+	Priority int               `json:"priority" validate:"omitempty,oneof=1 2 3"`
+	Tags     []string          `json:"tags" validate:"max=2,dive,min=3"`
+	Lines    []validateLine    `json:"lines" validate:"required,dive"`
+	Labels   map[string]string `json:"labels" validate:"dive,max=3"`
+	Address  *validateAddress  `json:"address"`
+	Notes    string            `json:"notes" validate:"omitempty,gte=3"`
+}
+
+func validOrder() validateOrder {
+	return validateOrder{
+		Email:    "ops@example.com",
+		Name:     "desk",
+		Status:   "open",
+		Priority: 2,
+		Tags:     []string{"red"},
+		Lines:    []validateLine{{SKU: "ABCD1234", Quantity: 1}},
+		Labels:   map[string]string{"a": "b"},
+		Address:  &validateAddress{City: "Oslo", Zip: "12345"},
+	}
+}
+
+func violations(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	var gerr *Error
+	require.True(t, errors.As(err, &gerr), "expected *Error, got %v", err)
+	assert.Equal(t, http.StatusUnprocessableEntity, gerr.Status())
+	assert.ErrorIs(t, err, ErrValidationFailed)
+
+	fields, ok := gerr.Extensions()["fields"].([]FieldError)
+	require.True(t, ok)
+
+	ret := map[string]string{}
+	for _, fe := range fields {
+		ret[fe.Field] = fe.Message
+	}
+	return ret
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("it should accept a valid struct", func(t *testing.T) {
+		o := validOrder()
+		assert.NoError(t, Validate(&o))
+		assert.NoError(t, Validate(o))
+	})
+
+	t.Run("it should skip rules on empty optional fields", func(t *testing.T) {
+		o := validOrder()
+		o.Status, o.Priority, o.Tags, o.Labels, o.Address = "", 0, nil, nil, nil
+		assert.NoError(t, Validate(o))
+	})
+
+	t.Run("it should apply rules to zero values without omitempty", func(t *testing.T) {
+		type zero struct {
+			Quantity int     `json:"quantity" validate:"min=1"`
+			Code     string  `json:"code" validate:"len=3"`
+			Kind     string  `json:"kind" validate:"oneof=a b"`
+			Limit    *int    `json:"limit" validate:"min=1"`
+			Note     *string `json:"note" validate:"len=3"`
+		}
+
+		assert.Equal(t, map[string]string{
+			"quantity": "must be at least 1",
+			"code":     "length must be exactly 3",
+			"kind":     "must be one of: a, b",
+		}, violations(t, Validate(zero{})))
+
+		limit := 0
+		assert.Equal(t, map[string]string{
+			"quantity": "must be at least 1",
+			"code":     "length must be exactly 3",
+			"kind":     "must be one of: a, b",
+			"limit":    "must be at least 1",
+		}, violations(t, Validate(zero{Limit: &limit})))
+	})
+
+	t.Run("it should report every violation", func(t *testing.T) {
+		o := validateOrder{
+			Email:    "not-an-email",
+			Name:     "abcdef",
+			Status:   "pending",
+			Priority: 7,
+			Tags:     []string{"ok!", "no", "x"},
+			Labels:   map[string]string{"k": "long"},
+			Address:  &validateAddress{Zip: "1234"},
+		}
+
+		assert.Equal(t, map[string]string{
+			"email":        "must be a valid email address",
+			"name":         "length must be at most 5",
+			"status":       "must be one of: open, closed",
+			"priority":     "must be one of: 1, 2, 3",
+			"tags":         "length must be at most 2",
+			"tags[1]":      "length must be at least 3",
+			"tags[2]":      "length must be at least 3",
+			"lines":        "is required",
+			"labels[k]":    "length must be at most 3",
+			"address.city": "is required",
+			"address.zip":  "must match ^[0-9]{5}(-[0-9]{4})?$",
+		}, violations(t, Validate(o)))
+	})
+
+	t.Run("it should dive into struct elements", func(t *testing.T) {
+		o := validOrder()
+		o.Lines = []validateLine{{SKU: "ABCD1234", Quantity: 1}, {SKU: "short", Quantity: 100}}
+
+		assert.Equal(t, map[string]string{
+			"lines[1].sku":      "length must be exactly 8",
+			"lines[1].quantity": "must be at most 99",
+		}, violations(t, Validate(o)))
+	})
+
+	t.Run("it should honour required:true", func(t *testing.T) {
+		o := validOrder()
+		o.Name = ""
+		assert.Equal(t, map[string]string{"name": "is required"}, violations(t, Validate(o)))
+	})
+
+	t.Run("it should reject non structs", func(t *testing.T) {
+		assert.Error(t, Validate(42))
+		assert.NoError(t, Validate((*validateOrder)(nil)))
+	})
+
+	t.Run("it should answer 500 on malformed rules", func(t *testing.T) {
+		type bad struct {
+			N int `validate:"min=abc"`
+		}
+
+		err := Validate(bad{})
+		require.Error(t, err)
+		assert.Equal(t, http.StatusInternalServerError, AsError(err).Status())
+		assert.Contains(t, err.Error(), `invalid min rule "min=abc"`)
+
+		type nested struct {
+			Bad bad `json:"bad"`
+		}
+		assert.Equal(t, http.StatusInternalServerError, AsError(Validate(nested{})).Status())
+	})
+
+	t.Run("it should panic on malformed rules at registration", func(t *testing.T) {
+		type bad struct {
+			N int `validate:"min=abc"`
+		}
+		assert.Panics(t, func() { Describe("bad").Input(bad{}) })
+	})
+}
+
+func TestValidateRouteDoc(t *testing.T) {
+	params := map[string]RouteParam{}
+	for _, p := range Input(validateOrder{}).Params() {
+		params[p.Name] = p
+	}
+
+	assert.True(t, params["email"].Required)
+	assert.True(t, params["name"].Required)
+	assert.False(t, params["status"].Required)
+	assert.Equal(t, []string{"email"}, params["email"].Rules)
+	assert.Equal(t, []string{"min=2", "max=5"}, params["name"].Rules)
+	assert.Equal(t, []string{"max=2", "dive", "min=3"}, params["tags"].Rules)
+
+	t.Run("it should feed the OpenAPI schema", func(t *testing.T) {
+		root := NewRouteRoot()
+		root.Post("/orders", tableHandler, Input(validateOrder{}))
+
+		schemas := root.OpenAPI(OpenAPIInfo{}).Components.Schemas
+		order := schemas["validateOrder"]
+		require.NotNil(t, order)
+
+		two, five, three := 2, 5, 3
+		assert.Equal(t, "email", order.Properties["email"].Format)
+		assert.Equal(t, &two, order.Properties["name"].MinLength)
+		assert.Equal(t, &five, order.Properties["name"].MaxLength)
+		assert.Equal(t, []any{"open", "closed"}, order.Properties["status"].Enum)
+		assert.Equal(t, []any{1.0, 2.0, 3.0}, order.Properties["priority"].Enum)
+		assert.Equal(t, &two, order.Properties["tags"].MaxItems)
+		assert.Equal(t, &three, order.Properties["tags"].Items.MinLength)
+		assert.Equal(t, &three, order.Properties["labels"].AdditionalProperties.MaxLength)
+
+		line := schemas["validateLine"]
+		require.NotNil(t, line)
+		one, ninetyNine := 1.0, 99.0
+		assert.Equal(t, &one, line.Properties["quantity"].Minimum)
+		assert.Equal(t, &ninetyNine, line.Properties["quantity"].Maximum)
+
+		assert.Equal(t, "^[0-9]{5}(-[0-9]{4})?$", schemas["validateAddress"].Properties["zip"].Pattern)
+	})
+}
+
+func TestValidateInputOption(t *testing.T) {
+	run := func(t *testing.T, opts Options, body string, decode func(*WebContext, *validateLine) error) error {
+		t.Helper()
+
+		var decodeErr error
+		serveTestApp(t, opts, func(r *Route) {
+			r.Post("/lines", func(wctx *WebContext) {
+				var in validateLine
+				decodeErr = decode(wctx, &in)
+			})
+		}, httptest.NewRequest(http.MethodPost, "/lines", strings.NewReader(body)))
+		return decodeErr
+	}
+
+	marshal := func(wctx *WebContext, in *validateLine) error { return wctx.Marshal(in) }
+	bind := func(wctx *WebContext, in *validateLine) error { return wctx.Bind(in) }
+
+	t.Run("it should not validate by default", func(t *testing.T) {
+		assert.NoError(t, run(t, Options{}, `{"sku":"x"}`, marshal))
+		assert.NoError(t, run(t, Options{}, `{"sku":"x"}`, bind))
+	})
+
+	t.Run("it should validate Marshal and Bind when enabled", func(t *testing.T) {
+		expected := map[string]string{"sku": "length must be exactly 8"}
+
+		assert.Equal(t, expected, violations(t, run(t, Options{ValidateInput: true}, `{"sku":"x","quantity":1}`, marshal)))
+		assert.Equal(t, expected, violations(t, run(t, Options{ValidateInput: true}, `{"sku":"x","quantity":1}`, bind)))
+	})
+}
@@ -107,7 +107,10 @@ type WebContext struct {
 	// Path segments (zero alloc storage)
 	segmentBuf [20]string
 
-	body []byte
+	body    []byte
+	bodyErr error
+
+	jsonOptions JSONOptions
 }
 
 // Some helper functions
@@ -136,6 +139,10 @@ func (wctx *WebContext) Path() string              { return wctx.path }
 // - RenderText: Renders data as plain text.
 // - RenderHTML: Renders data as HTML.
 // - RenderData: Renders raw byte data.
+// - RenderNegotiated: Picks the format from the Accept header (406 when nothing matches).
+//
+// GET/HEAD responses carrying an ETag or Last-Modified (SetETag, SetLastModified,
+// or Options.ETags) answer matching conditional requests with 304.
 //
 // Example:
 //
@@ -160,44 +167,96 @@ func (wctx *WebContext) RenderXML(data any)                   { Render(wctx, For
 func (wctx *WebContext) RenderData(data []byte)               { Render(wctx, FormatTypeData, data) }
 func (wctx *WebContext) RenderText(data string)               { Render(wctx, FormatTypeText, data) }
 func (wctx *WebContext) RenderHTML(data string)               { Render(wctx, FormatTypeHTML, data) }
+func (wctx *WebContext) RenderError(err error)                { RenderError(wctx, err) }
+func (wctx *WebContext) RenderNegotiated(data any)            { Render(wctx, FormatTypeNegotiate, data) }
 
 func (wctx *WebContext) URLParams() *RouteVars {
-	if wctx.varsLoaded {
-		return &wctx.vars
+	if !wctx.varsLoaded {
+		wctx.loadVars()
 	}
 
+	return &wctx.vars
+}
+
+// loadVars fills path variables followed by any host label captures.
+func (wctx *WebContext) loadVars() {
 	fillRouteVariables(&wctx.vars, wctx.route, wctx.segments)
-	wctx.varsLoaded = true
 
-	return &wctx.vars
+	if wctx.route != nil {
+		tree := wctx.route.root
+		if tree == nil {
+			tree = wctx.route
+		}
+
+		if tree.host != nil {
+			tree.host.match(stripHostPort(wctx.request.Host), &wctx.vars)
+		}
+	}
+
+	wctx.varsLoaded = true
 }
 
 // Marshal decodes JSON from the buffered request body into out.
 // Suitable for small, known-size payloads (JSON APIs, small forms).
 // Repeated calls are safe — body is cached after the first read.
+// Body read errors (e.g. the 413 of LimitBody) are returned as is, and
+// Options.JSON / WithJSONOptions apply.
+// With Options.ValidateInput, decoded structs are also checked with Validate.
 func (wctx *WebContext) Marshal(out any) error {
-	return json.Unmarshal(wctx.Body(), out)
+	body, err := wctx.Body()
+	if err != nil {
+		return err
+	}
+
+	if err := wctx.unmarshalJSON(body, out); err != nil {
+		return err
+	}
+	return wctx.validateInput(out)
+}
+
+// validateInput runs Validate on struct values when the application opted
+// in through Options.ValidateInput.
+func (wctx *WebContext) validateInput(out any) error {
+	if wctx.ctx == nil {
+		return nil
+	}
+
+	if a := wctx.ctx.Application(); a == nil || !a.validateInput || structType(out) == nil {
+		return nil
+	}
+	return Validate(out)
 }
 
 // MarshalStream decodes JSON via a streaming decoder without buffering the body.
 // Prefer this for large payloads or NDJSON where you don't need the raw bytes.
 // NOTE: mutually exclusive with Body() — once the stream is consumed it cannot be re-read.
 func (wctx *WebContext) MarshalStream(out any) error {
-	return json.NewDecoder(wctx.request.Body).Decode(out)
+	var r io.Reader = wctx.request.Body
+	if max := wctx.jsonOptions.MaxDepth; max > 0 {
+		r = &depthLimitedReader{r: r, max: max}
+	}
+
+	dec := json.NewDecoder(r)
+	if wctx.jsonOptions.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(out)
 }
 
-// Body buffers the full request body into memory and returns it.
-// Repeated calls return the cached result with no additional reads.
+// Body buffers the full request body into memory and returns it with the
+// read error, such as the 413 *Error of LimitBody or a client that went
+// away mid-upload. Repeated calls return the cached result and error with
+// no additional reads.
 // Use BodyReader() instead when you need streaming access or are handling large payloads.
 //
 // Safe to call concurrently; internally serialised with mu.
-func (wctx *WebContext) Body() []byte {
+func (wctx *WebContext) Body() ([]byte, error) {
 	// Fast path — already buffered (no lock needed for read after first store).
 	wctx.mu.RLock()
 	if wctx.body != nil {
-		b := wctx.body
+		b, err := wctx.body, wctx.bodyErr
 		wctx.mu.RUnlock()
-		return b
+		return b, err
 	}
 	wctx.mu.RUnlock()
 
@@ -206,26 +265,35 @@ func (wctx *WebContext) Body() []byte {
 
 	// Double-check after acquiring write lock (another goroutine may have populated it).
 	if wctx.body != nil {
-		return wctx.body
+		return wctx.body, wctx.bodyErr
 	}
 
 	// Guard against nil or sentinel NoBody — treat as empty payload.
 	if wctx.request.Body == nil || wctx.request.Body == http.NoBody {
 		wctx.body = []byte{}
-		return wctx.body
+		return wctx.body, nil
 	}
 
 	var buf *bytes.Buffer
-	if cl := wctx.request.ContentLength; cl > 0 {
+	if cl := wctx.request.ContentLength; cl > 0 && !wctx.overLimit(cl) {
 		buf = bytes.NewBuffer(make([]byte, 0, cl))
 	} else {
 		buf = &bytes.Buffer{}
 	}
 
-	io.Copy(buf, wctx.request.Body) //nolint:errcheck
+	_, wctx.bodyErr = io.Copy(buf, wctx.request.Body)
 	wctx.body = buf.Bytes()
-	wctx.request.Body = io.NopCloser(bytes.NewReader(wctx.body))
-	return wctx.body
+	if wctx.bodyErr == nil {
+		wctx.request.Body = io.NopCloser(bytes.NewReader(wctx.body))
+	}
+	return wctx.body, wctx.bodyErr
+}
+
+// overLimit reports whether n exceeds the LimitBody cap, so a lying
+// Content-Length cannot make Body preallocate past it.
+func (wctx *WebContext) overLimit(n int64) bool {
+	lb, ok := wctx.request.Body.(*maxBytesReader)
+	return ok && n > lb.limit
 }
 
 // BodyReader returns the raw request body as a streaming io.Reader.
@@ -235,15 +303,27 @@ func (wctx *WebContext) BodyReader() io.Reader {
 	return wctx.request.Body
 }
 
-// RequestBody returns the buffered request body.
+// RequestBody returns the buffered request body, dropping the read error.
 //
 // Deprecated: use Body() instead. RequestBody() will be removed in a future release.
-func (wctx *WebContext) RequestBody() []byte { return wctx.Body() }
+func (wctx *WebContext) RequestBody() []byte {
+	b, _ := wctx.Body()
+	return b
+}
 
 func (wctx *WebContext) Write(b []byte) (int, error) {
 	return wctx.writer.Write(b)
 }
 
+// WithResponse replaces the response writer, e.g. for middleware that
+// transforms the body. The previous writer must be restored before the
+// middleware returns, since the pooled WebContext reuses it for the next
+// request.
+func (wctx *WebContext) WithResponse(w WrapResponseWriter) *WebContext {
+	wctx.writer = w
+	return wctx
+}
+
 // NewWebContext returns a new web context
 func NewWebContext(parent context.Context, r *http.Request, w http.ResponseWriter) *WebContext {
 	if parent == nil {
@@ -284,6 +364,8 @@ func NewWebContext(parent context.Context, r *http.Request, w http.ResponseWrite
 	// ID generation
 	wctx.requestID = makeRequestID(wctx.reqIDBuf[:])
 
+	wctx.applyRequestLimits()
+
 	wctx.fillSegments(r.URL.Path)
 	return wctx
 }
@@ -321,6 +403,7 @@ func (wctx *WebContext) Reset(parent context.Context, r *http.Request, w http.Re
 	// Reset other fields
 	wctx.route = nil
 	wctx.body = nil
+	wctx.applyRequestLimits()
 
 	// Re-gen ID
 	wctx.requestID = makeRequestID(wctx.reqIDBuf[:])
@@ -146,14 +146,16 @@ func TestWebContextBody(t *testing.T) {
 
 	t.Run("nil body returns empty slice", func(t *testing.T) {
 		wctx := newCtx(nil)
-		b := wctx.Body()
+		b, err := wctx.Body()
+		assert.NoError(t, err)
 		assert.NotNil(t, b)
 		assert.Empty(t, b)
 	})
 
 	t.Run("http.NoBody returns empty slice", func(t *testing.T) {
 		wctx := newCtx(http.NoBody)
-		b := wctx.Body()
+		b, err := wctx.Body()
+		assert.NoError(t, err)
 		assert.NotNil(t, b)
 		assert.Empty(t, b)
 	})
@@ -161,8 +163,9 @@ func TestWebContextBody(t *testing.T) {
 	t.Run("normal body reads and caches", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"hello":"world"}`))
 		wctx := NewTestWebContext(req, httptest.NewRecorder())
-		b1 := wctx.Body()
-		b2 := wctx.Body()
+		b1, err := wctx.Body()
+		assert.NoError(t, err)
+		b2, _ := wctx.Body()
 		assert.Equal(t, `{"hello":"world"}`, string(b1))
 		assert.Equal(t, b1, b2, "second call must return cached bytes")
 	})
@@ -178,7 +181,7 @@ func TestWebContextBody(t *testing.T) {
 		for i := range n {
 			go func(idx int) {
 				defer wg.Done()
-				results[idx] = wctx.Body()
+				results[idx], _ = wctx.Body()
 			}(i)
 		}
 		wg.Wait()
@@ -223,6 +226,6 @@ func BenchmarkWebContextBody(b *testing.B) {
 	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = wctx.Body()
+		_, _ = wctx.Body()
 	}
 }
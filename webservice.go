@@ -2,7 +2,9 @@ package golly
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"sort"
@@ -10,7 +12,9 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/segmentio/encoding/json"
 	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
 )
 
 type WebService struct {
@@ -43,25 +47,75 @@ func (ws *WebService) Initialize(app *Application) error {
 }
 
 func (ws *WebService) Commands() []*cobra.Command {
-	return []*cobra.Command{
-		{
-			Use:   "routes",
-			Short: "List all routes",
-			Run: Command(func(app *Application, cmd *cobra.Command, args []string) error {
-				fmt.Println("Listing Routes:")
-
-				lines := buildPath(app.routes, "")
-				sort.Strings(lines)
-
-				w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-				fmt.Fprintln(w, "METHOD\tPATH\tDESCRIPTION\tQUERY\tINPUT\tOUTPUT")
-				for _, line := range lines {
-					fmt.Fprintln(w, line)
-				}
-				w.Flush()
-				return nil
-			}),
-		},
+	routes := &cobra.Command{
+		Use:   "routes",
+		Short: "List all routes",
+		Run: Command(func(app *Application, cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			return writeRoutes(os.Stdout, app.routes, format)
+		}),
+	}
+	routes.Flags().String("format", "table", "output format (table, json, yaml)")
+
+	openapi := &cobra.Command{
+		Use:   "openapi",
+		Short: "Print the OpenAPI document for all routes",
+		Run: Command(func(app *Application, cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			doc := app.routes.OpenAPI(OpenAPIInfo{Title: app.Name, Version: app.Version})
+			return encodeTo(os.Stdout, doc, format)
+		}),
+	}
+	openapi.Flags().String("format", "json", "output format (json, yaml)")
+
+	return []*cobra.Command{routes, openapi}
+}
+
+// writeRoutes prints the route table in the requested format.
+func writeRoutes(out io.Writer, routes *Route, format string) error {
+	switch format {
+	case "json", "yaml", "yml":
+		return encodeTo(out, routes.Table(), format)
+
+	case "", "table":
+		fmt.Fprintln(out, "Listing Routes:")
+
+		lines := buildPath(routes, "")
+		sort.Strings(lines)
+
+		w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "METHOD\tPATH\tDESCRIPTION\tQUERY\tINPUT\tOUTPUT")
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("unknown routes format %q (expected table, json or yaml)", format)
+	}
+}
+
+// encodeTo writes v as indented JSON or YAML.
+func encodeTo(out io.Writer, v any, format string) error {
+	switch format {
+	case "", "json":
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(b))
+		return err
+
+	case "yaml", "yml":
+		enc := yaml.NewEncoder(out)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+
+	default:
+		return fmt.Errorf("unknown format %q (expected json or yaml)", format)
 	}
 }
 
@@ -83,9 +137,11 @@ func (ws *WebService) Stop() error {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		if err := ws.server.Shutdown(ctx); err != nil {
-			return err
-		}
+		// Hijacked connections are not tracked by Shutdown, so WebSockets
+		// get their own close handshake
+		return errors.Join(
+			ws.server.Shutdown(ctx),
+			ws.application.CloseWebSockets(ctx))
 	}
 
 	return nil
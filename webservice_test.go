@@ -1,8 +1,12 @@
 package golly
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/segmentio/encoding/json"
+	"go.yaml.in/yaml/v3"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -39,8 +43,11 @@ func TestWebService(t *testing.T) {
 
 	t.Run("Commands", func(t *testing.T) {
 		cmds := ws.Commands()
-		assert.Len(t, cmds, 1)
+		assert.Len(t, cmds, 2)
 		assert.Equal(t, "routes", cmds[0].Use)
+		assert.NotNil(t, cmds[0].Flags().Lookup("format"))
+		assert.Equal(t, "openapi", cmds[1].Use)
+		assert.NotNil(t, cmds[1].Flags().Lookup("format"))
 	})
 
 	t.Run("IsRunning", func(t *testing.T) {
@@ -50,3 +57,39 @@ func TestWebService(t *testing.T) {
 		ws.running.Store(false)
 	})
 }
+
+func TestWriteRoutes(t *testing.T) {
+	root := NewRouteRoot()
+	root.Get("/users/{id:int}", noOpHandler, Describe("show user").Name("users.show"))
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeRoutes(&buf, root, "table"))
+		assert.Contains(t, buf.String(), "/users/{id:int}")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeRoutes(&buf, root, "json"))
+
+		var out []map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		require.Len(t, out, 1)
+		assert.Equal(t, "/users/{id:int}", out[0]["path"])
+		assert.Equal(t, "users.show", out[0]["name"])
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeRoutes(&buf, root, "yaml"))
+
+		var out []map[string]any
+		require.NoError(t, yaml.Unmarshal(buf.Bytes(), &out))
+		require.Len(t, out, 1)
+		assert.Equal(t, "GET", out[0]["method"])
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		assert.Error(t, writeRoutes(&bytes.Buffer{}, root, "xml"))
+	})
+}
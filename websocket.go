@@ -0,0 +1,827 @@
+package golly
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/segmentio/encoding/json"
+)
+
+var (
+	ErrNotWebSocket        = errors.New("not a websocket handshake")
+	ErrWebSocketVersion    = errors.New("unsupported websocket version")
+	ErrWebSocketOrigin     = errors.New("websocket origin not allowed")
+	ErrWebSocketClosed     = errors.New("websocket closed")
+	ErrWebSocketProtocol   = errors.New("websocket protocol error")
+	ErrMessageTooLarge     = errors.New("websocket message too large")
+	ErrInvalidMessageType  = errors.New("invalid websocket message type")
+	ErrServerShuttingDown  = errors.New("server shutting down")
+	ErrWebSocketNoHijacker = errors.New("response writer cannot be hijacked")
+)
+
+const (
+	defaultMaxMessageSize = 1 << 20
+	defaultWSWriteTimeout = 10 * time.Second
+	defaultWSCloseTimeout = 5 * time.Second
+
+	maxControlPayload = 125
+	websocketGUID     = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+// MessageType is the type of a WebSocket data message.
+type MessageType int
+
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+)
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// CloseCode is a WebSocket close status code (RFC 6455 section 7.4).
+type CloseCode int
+
+const (
+	CloseNormal          CloseCode = 1000
+	CloseGoingAway       CloseCode = 1001
+	CloseProtocolError   CloseCode = 1002
+	CloseUnsupportedData CloseCode = 1003
+	CloseNoStatus        CloseCode = 1005
+	CloseAbnormal        CloseCode = 1006
+	CloseInvalidPayload  CloseCode = 1007
+	ClosePolicyViolation CloseCode = 1008
+	CloseMessageTooBig   CloseCode = 1009
+	CloseInternalError   CloseCode = 1011
+)
+
+// validOnWire reports whether a peer may send code in a close frame.
+func (c CloseCode) validOnWire() bool {
+	switch {
+	case c >= 1000 && c <= 1003, c >= 1007 && c <= 1011:
+		return true
+	case c >= 3000 && c <= 4999:
+		return true
+	}
+	return false
+}
+
+// CloseError is returned by reads once the connection is closed. It
+// matches ErrWebSocketClosed with errors.Is.
+type CloseError struct {
+	Code   CloseCode
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return "websocket closed: " + strconv.Itoa(int(e.Code))
+	}
+	return "websocket closed: " + strconv.Itoa(int(e.Code)) + " " + e.Reason
+}
+
+func (e *CloseError) Unwrap() error { return ErrWebSocketClosed }
+
+// WebSocketOptions configures UpgradeWebSocket. Zero values use the
+// defaults noted on each field.
+type WebSocketOptions struct {
+	// Subprotocols the server speaks, in order of preference
+	Subprotocols []string
+
+	// MaxMessageSize caps a reassembled message; larger ones close the
+	// connection with 1009 (default 1 MiB)
+	MaxMessageSize int64
+
+	// CheckOrigin accepts or rejects the handshake (default: a present
+	// Origin header must match the request host)
+	CheckOrigin func(r *http.Request) bool
+
+	// PingInterval sends pings to keep the connection alive (0 disables)
+	PingInterval time.Duration
+
+	// IdleTimeout closes the connection when nothing, pongs included, is
+	// read for this long (default 2*PingInterval, none without pings)
+	IdleTimeout time.Duration
+
+	// WriteTimeout bounds each frame write (default 10s)
+	WriteTimeout time.Duration
+
+	// CloseTimeout bounds the wait for the peer's close frame (default 5s)
+	CloseTimeout time.Duration
+}
+
+func (o WebSocketOptions) withDefaults() WebSocketOptions {
+	if o.MaxMessageSize <= 0 {
+		o.MaxMessageSize = defaultMaxMessageSize
+	}
+	if o.CheckOrigin == nil {
+		o.CheckOrigin = sameOrigin
+	}
+	if o.IdleTimeout <= 0 && o.PingInterval > 0 {
+		o.IdleTimeout = 2 * o.PingInterval
+	}
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = defaultWSWriteTimeout
+	}
+	if o.CloseTimeout <= 0 {
+		o.CloseTimeout = defaultWSCloseTimeout
+	}
+	return o
+}
+
+// WebSocket is a server side RFC 6455 connection. Writes are safe for
+// concurrent use; reads must come from a single goroutine. Pings are
+// answered and close frames echoed while reading.
+type WebSocket struct {
+	// Subprotocol is the negotiated subprotocol, empty when none
+	Subprotocol string
+
+	conn net.Conn
+	br   *bufio.Reader
+	bw   *bufio.Writer
+	opts WebSocketOptions
+
+	ctx    *Context
+	cancel context.CancelFunc
+	app    *Application
+
+	readMu  sync.Mutex
+	readErr error
+
+	writeMu   sync.Mutex
+	closeSent atomic.Bool
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// UpgradeWebSocket completes the WebSocket handshake and takes over the
+// connection. Handshake failures are *Errors the caller renders: 400 for
+// requests that are not a handshake, 426 for other protocol versions, 403
+// for a rejected Origin and 503 while the server shuts down. Once the
+// connection is taken over, errors are plain and nothing is left to render.
+//
+// The connection belongs to the handler: it is closed (1000) when the
+// handler returns, and Context() is done once it closes. Open connections
+// are closed with 1001 and drained by Application.CloseWebSockets, which the
+// web services call on Stop.
+//
+// Example:
+//
+//	app.Routes().Get("/ws", func(wctx *golly.WebContext) {
+//	    ws, err := wctx.UpgradeWebSocket(golly.WebSocketOptions{PingInterval: 30 * time.Second})
+//	    if err != nil {
+//	        wctx.RenderError(err)
+//	        return
+//	    }
+//
+//	    for {
+//	        typ, msg, err := ws.ReadMessage()
+//	        if err != nil {
+//	            return
+//	        }
+//	        if err := ws.WriteMessage(typ, msg); err != nil {
+//	            return
+//	        }
+//	    }
+//	})
+func (wctx *WebContext) UpgradeWebSocket(opts ...WebSocketOptions) (*WebSocket, error) {
+	var opt WebSocketOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	opt = opt.withDefaults()
+
+	r := wctx.request
+	if r.Method != http.MethodGet ||
+		!headerHasToken(r.Header, "Connection", "upgrade") ||
+		!headerHasToken(r.Header, "Upgrade", "websocket") {
+		return nil, NewError(http.StatusBadRequest, ErrNotWebSocket)
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		wctx.ResponseHeaders().Set("Sec-WebSocket-Version", "13")
+		return nil, NewError(http.StatusUpgradeRequired, ErrWebSocketVersion)
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if k, err := base64.StdEncoding.DecodeString(key); err != nil || len(k) != 16 {
+		return nil, NewError(http.StatusBadRequest, ErrNotWebSocket)
+	}
+
+	if !opt.CheckOrigin(r) {
+		return nil, NewError(http.StatusForbidden, ErrWebSocketOrigin)
+	}
+
+	hj, ok := wctx.writer.(http.Hijacker)
+	if !ok {
+		return nil, NewError(http.StatusInternalServerError, ErrWebSocketNoHijacker)
+	}
+
+	ws := &WebSocket{
+		Subprotocol: selectSubprotocol(r.Header, opt.Subprotocols),
+		opts:        opt,
+		app:         wctx.Application(),
+		closed:      make(chan struct{}),
+	}
+
+	if ws.app != nil && ws.app.websockets.isClosing() {
+		return nil, NewError(http.StatusServiceUnavailable, ErrServerShuttingDown)
+	}
+
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return nil, NewError(http.StatusInternalServerError, err)
+	}
+
+	ws.conn, ws.br, ws.bw = conn, brw.Reader, brw.Writer
+	ws.ctx, ws.cancel = WithCancel(wctx.ctx)
+
+	// Server read/write timeouts no longer apply to the hijacked conn
+	conn.SetDeadline(time.Time{}) //nolint:errcheck
+
+	if err := ws.writeHandshake(key, wctx.ResponseHeaders()); err != nil {
+		ws.closeConn()
+		return nil, err
+	}
+
+	// Shutdown may have started since the check above
+	if ws.app != nil && !ws.app.websockets.add(ws) {
+		ws.Close(CloseGoingAway, "server shutting down") //nolint:errcheck
+		return nil, ErrServerShuttingDown
+	}
+
+	// The request context ends with the handler, taking the connection
+	// with it
+	context.AfterFunc(ws.ctx, func() { ws.Close(CloseNormal, "") }) //nolint:errcheck
+
+	if opt.PingInterval > 0 {
+		go ws.pingLoop()
+	}
+
+	return ws, nil
+}
+
+// Context is derived from the request and done once the connection
+// closes.
+func (ws *WebSocket) Context() *Context { return ws.ctx }
+
+// ReadMessage returns the next data message, reassembling fragments. A
+// peer close yields a *CloseError; protocol violations and oversized
+// messages close the connection with the matching code and return
+// ErrWebSocketProtocol or ErrMessageTooLarge. Once an error is returned,
+// every later read returns it again.
+func (ws *WebSocket) ReadMessage() (MessageType, []byte, error) {
+	ws.readMu.Lock()
+	defer ws.readMu.Unlock()
+
+	if ws.readErr != nil {
+		return 0, nil, ws.readErr
+	}
+
+	typ, msg, err := ws.readMessage()
+	if err != nil {
+		ws.readErr = err
+		ws.closeConn()
+	}
+	return typ, msg, err
+}
+
+// ReadJSON reads the next message and decodes it into v.
+func (ws *WebSocket) ReadJSON(v any) error {
+	_, msg, err := ws.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(msg, v)
+}
+
+// WriteMessage sends data as a single frame of the given type.
+func (ws *WebSocket) WriteMessage(typ MessageType, data []byte) error {
+	if typ != TextMessage && typ != BinaryMessage {
+		return ErrInvalidMessageType
+	}
+	return ws.writeFrame(byte(typ), data)
+}
+
+// WriteText sends s as a text message.
+func (ws *WebSocket) WriteText(s string) error {
+	return ws.WriteMessage(TextMessage, []byte(s))
+}
+
+// WriteJSON sends v encoded as a JSON text message.
+func (ws *WebSocket) WriteJSON(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(TextMessage, b)
+}
+
+// Ping sends a ping with up to 125 bytes of data.
+func (ws *WebSocket) Ping(data []byte) error {
+	if len(data) > maxControlPayload {
+		return fmt.Errorf("%w: control payload over %d bytes", ErrWebSocketProtocol, maxControlPayload)
+	}
+	return ws.writeFrame(opPing, data)
+}
+
+// Close starts the close handshake with code and reason, then waits up to
+// CloseTimeout for the peer's close frame before dropping the connection.
+// Calling it on a closed connection is a no-op.
+func (ws *WebSocket) Close(code CloseCode, reason string) error {
+	if ws.isClosed() {
+		return nil
+	}
+
+	err := ws.sendClose(code, reason)
+	ws.awaitClose()
+
+	if errors.Is(err, ErrWebSocketClosed) {
+		return nil
+	}
+	return err
+}
+
+// awaitClose waits for the peer's close frame: through the active reader
+// when there is one, by draining the connection otherwise.
+func (ws *WebSocket) awaitClose() {
+	deadline := time.Now().Add(ws.opts.CloseTimeout)
+	ws.conn.SetReadDeadline(deadline) //nolint:errcheck
+
+	if ws.readMu.TryLock() {
+		defer ws.readMu.Unlock()
+
+		for ws.readErr == nil {
+			if _, _, err := ws.readMessage(); err != nil {
+				ws.readErr = err
+			}
+		}
+		ws.closeConn()
+		return
+	}
+
+	select {
+	case <-ws.closed:
+	case <-time.After(time.Until(deadline)):
+		ws.closeConn()
+	}
+}
+
+func (ws *WebSocket) isClosed() bool {
+	select {
+	case <-ws.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeConn drops the connection, ends its context and stops tracking it.
+func (ws *WebSocket) closeConn() {
+	ws.closeOnce.Do(func() {
+		close(ws.closed)
+		ws.conn.Close()
+		ws.cancel()
+		ws.untrack()
+	})
+}
+
+func (ws *WebSocket) untrack() {
+	if ws.app != nil {
+		ws.app.websockets.remove(ws)
+	}
+}
+
+func (ws *WebSocket) pingLoop() {
+	ticker := time.NewTicker(ws.opts.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ws.closed:
+			return
+		case <-ticker.C:
+			if err := ws.writeFrame(opPing, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (ws *WebSocket) writeHandshake(key string, headers http.Header) error {
+	bw := ws.bw
+
+	bw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: ")
+	bw.WriteString(acceptKey(key))
+	bw.WriteString("\r\n")
+
+	if ws.Subprotocol != "" {
+		bw.WriteString("Sec-WebSocket-Protocol: " + ws.Subprotocol + "\r\n")
+	}
+
+	// Headers set before the upgrade (cookies and the like) go out too
+	for name, values := range headers {
+		if skipHandshakeHeader(name) {
+			continue
+		}
+		for _, v := range values {
+			bw.WriteString(name + ": " + v + "\r\n")
+		}
+	}
+
+	bw.WriteString("\r\n")
+	return bw.Flush()
+}
+
+// readMessage reads frames until a data message completes, handling
+// control frames on the way. Failures close the connection with the
+// matching code.
+func (ws *WebSocket) readMessage() (MessageType, []byte, error) {
+	var (
+		typ     MessageType
+		msg     []byte
+		inFrame bool
+	)
+
+	for {
+		fin, op, payload, err := ws.readFrame(ws.opts.MaxMessageSize - int64(len(msg)))
+		if err != nil {
+			return 0, nil, ws.readFailure(err)
+		}
+
+		switch op {
+		case opPing:
+			if err := ws.writeFrame(opPong, payload); err != nil && !errors.Is(err, ErrWebSocketClosed) {
+				return 0, nil, err
+			}
+
+		case opPong:
+			// Reading it refreshed the idle deadline
+
+		case opClose:
+			return 0, nil, ws.receiveClose(payload)
+
+		case opText, opBinary:
+			if inFrame {
+				return 0, nil, ws.fail(CloseProtocolError, "data frame inside a fragmented message")
+			}
+			typ, msg, inFrame = MessageType(op), payload, true
+
+		case opContinuation:
+			if !inFrame {
+				return 0, nil, ws.fail(CloseProtocolError, "continuation without a message")
+			}
+			msg = append(msg, payload...)
+
+		default:
+			return 0, nil, ws.fail(CloseProtocolError, "unknown opcode "+strconv.Itoa(int(op)))
+		}
+
+		// Control frames may arrive between fragments; only a final data
+		// frame completes the message
+		if op < opClose && fin {
+			if typ == TextMessage && !utf8.Valid(msg) {
+				return 0, nil, ws.fail(CloseInvalidPayload, "invalid utf-8 in text message")
+			}
+			return typ, msg, nil
+		}
+	}
+}
+
+// wsFailure is a protocol violation detected while reading a frame.
+type wsFailure struct {
+	code   CloseCode
+	reason string
+}
+
+func (f wsFailure) Error() string { return f.reason }
+
+// readFrame reads one frame, unmasking the payload. Data payloads over
+// limit fail with 1009.
+func (ws *WebSocket) readFrame(limit int64) (bool, byte, []byte, error) {
+	if idle := ws.opts.IdleTimeout; idle > 0 && !ws.closeSent.Load() {
+		ws.conn.SetReadDeadline(time.Now().Add(idle)) //nolint:errcheck
+	}
+
+	var head [2]byte
+	if _, err := io.ReadFull(ws.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := head[0]&0x80 != 0
+	op := head[0] & 0x0f
+	masked := head[1]&0x80 != 0
+	length := int64(head[1] & 0x7f)
+
+	switch {
+	case head[0]&0x70 != 0:
+		return false, 0, nil, wsFailure{CloseProtocolError, "reserved bits set"}
+	case !masked:
+		return false, 0, nil, wsFailure{CloseProtocolError, "unmasked client frame"}
+	case op >= opClose && (!fin || length > maxControlPayload):
+		return false, 0, nil, wsFailure{CloseProtocolError, "invalid control frame"}
+	}
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(ws.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(ws.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		if ext[0]&0x80 != 0 {
+			return false, 0, nil, wsFailure{CloseProtocolError, "invalid frame length"}
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+
+	if op < opClose && length > limit {
+		return false, 0, nil, wsFailure{CloseMessageTooBig, "message over " + strconv.FormatInt(ws.opts.MaxMessageSize, 10) + " bytes"}
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(ws.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(ws.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i&3]
+	}
+
+	return fin, op, payload, nil
+}
+
+// readFailure maps a frame read error to what ReadMessage returns.
+func (ws *WebSocket) readFailure(err error) error {
+	var f wsFailure
+	if errors.As(err, &f) {
+		return ws.fail(f.code, f.reason)
+	}
+
+	if ws.isClosed() || ws.closeSent.Load() {
+		return ErrWebSocketClosed
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &CloseError{Code: CloseAbnormal}
+	}
+	return err
+}
+
+// fail closes the connection with code and returns the matching error.
+func (ws *WebSocket) fail(code CloseCode, reason string) error {
+	ws.sendClose(code, reason) //nolint:errcheck
+
+	if code == CloseMessageTooBig {
+		return fmt.Errorf("%w: %s", ErrMessageTooLarge, reason)
+	}
+	return fmt.Errorf("%w: %s", ErrWebSocketProtocol, reason)
+}
+
+// receiveClose validates the peer's close frame and echoes it unless the
+// handshake started on this side.
+func (ws *WebSocket) receiveClose(payload []byte) error {
+	ce := &CloseError{Code: CloseNoStatus}
+
+	switch {
+	case len(payload) == 1:
+		return ws.fail(CloseProtocolError, "invalid close frame")
+	case len(payload) >= 2:
+		ce.Code = CloseCode(binary.BigEndian.Uint16(payload))
+		ce.Reason = string(payload[2:])
+
+		if !ce.Code.validOnWire() {
+			return ws.fail(CloseProtocolError, "invalid close code")
+		}
+		if !utf8.ValidString(ce.Reason) {
+			return ws.fail(CloseInvalidPayload, "invalid utf-8 in close reason")
+		}
+	}
+
+	echo := ce.Code
+	if echo == CloseNoStatus {
+		echo = CloseNormal
+	}
+	ws.sendClose(echo, "") //nolint:errcheck
+
+	return ce
+}
+
+// sendClose writes the close frame once; later writes fail with
+// ErrWebSocketClosed.
+func (ws *WebSocket) sendClose(code CloseCode, reason string) error {
+	if len(reason) > maxControlPayload-2 {
+		reason = reason[:maxControlPayload-2]
+	}
+
+	payload := make([]byte, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	copy(payload[2:], reason)
+
+	return ws.writeFrame(opClose, payload)
+}
+
+func (ws *WebSocket) writeFrame(op byte, payload []byte) error {
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+
+	if ws.closeSent.Load() || ws.isClosed() {
+		return ErrWebSocketClosed
+	}
+	if op == opClose {
+		ws.closeSent.Store(true)
+	}
+
+	ws.conn.SetWriteDeadline(time.Now().Add(ws.opts.WriteTimeout)) //nolint:errcheck
+
+	var head [10]byte
+	head[0] = 0x80 | op
+
+	n := 2
+	switch l := len(payload); {
+	case l <= 125:
+		head[1] = byte(l)
+	case l <= 0xffff:
+		head[1] = 126
+		binary.BigEndian.PutUint16(head[2:], uint16(l))
+		n = 4
+	default:
+		head[1] = 127
+		binary.BigEndian.PutUint64(head[2:], uint64(l))
+		n = 10
+	}
+
+	ws.bw.Write(head[:n])
+	ws.bw.Write(payload)
+	return ws.bw.Flush()
+}
+
+// acceptKey computes Sec-WebSocket-Accept for a client key.
+func acceptKey(key string) string {
+	h := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// selectSubprotocol picks the first of ours the client offered.
+func selectSubprotocol(h http.Header, supported []string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+
+	var offered []string
+	for _, v := range h.Values("Sec-WebSocket-Protocol") {
+		for p := range strings.SplitSeq(v, ",") {
+			offered = append(offered, strings.TrimSpace(p))
+		}
+	}
+
+	for _, s := range supported {
+		for _, o := range offered {
+			if s == o {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for t := range strings.SplitSeq(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func skipHandshakeHeader(name string) bool {
+	switch http.CanonicalHeaderKey(name) {
+	case "Upgrade", "Connection", "Content-Length", "Content-Type", "Transfer-Encoding",
+		"Sec-Websocket-Accept", "Sec-Websocket-Protocol", "Sec-Websocket-Extensions":
+		return true
+	}
+	return false
+}
+
+// sameOrigin accepts requests without an Origin header (non-browser
+// clients) and browser requests from the host they target.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// webSocketSet tracks the open connections of an application.
+type webSocketSet struct {
+	mu      sync.Mutex
+	conns   map[*WebSocket]struct{}
+	closing bool
+}
+
+func (s *webSocketSet) add(ws *WebSocket) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closing {
+		return false
+	}
+	if s.conns == nil {
+		s.conns = map[*WebSocket]struct{}{}
+	}
+	s.conns[ws] = struct{}{}
+	return true
+}
+
+func (s *webSocketSet) isClosing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closing
+}
+
+func (s *webSocketSet) remove(ws *WebSocket) {
+	s.mu.Lock()
+	delete(s.conns, ws)
+	s.mu.Unlock()
+}
+
+func (s *webSocketSet) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns)
+}
+
+// CloseWebSockets sends a 1001 close frame to every open WebSocket and
+// waits for them to finish the close handshake. Upgrades are refused
+// (503) from then on, so nothing opened after the drain runs untracked.
+// Connections still open when ctx is done are dropped and ctx's error is
+// returned.
+func (a *Application) CloseWebSockets(ctx context.Context) error {
+	s := &a.websockets
+
+	s.mu.Lock()
+	s.closing = true
+	conns := make([]*WebSocket, 0, len(s.conns))
+	for ws := range s.conns {
+		conns = append(conns, ws)
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, ws := range conns {
+		wg.Go(func() { ws.Close(CloseGoingAway, "server shutting down") }) //nolint:errcheck
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		for _, ws := range conns {
+			ws.closeConn()
+		}
+		return ctx.Err()
+	}
+}
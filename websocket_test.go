@@ -0,0 +1,347 @@
+package golly
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wsClient is a minimal client side of RFC 6455 for tests.
+type wsClient struct {
+	t    *testing.T
+	conn net.Conn
+	br   *bufio.Reader
+	resp *http.Response
+}
+
+func dialWebSocket(t *testing.T, srv *httptest.Server, path string, headers map[string]string) *wsClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	require.NoError(t, req.Write(conn))
+
+	c := &wsClient{t: t, conn: conn, br: bufio.NewReader(conn)}
+	c.resp, err = http.ReadResponse(c.br, req)
+	require.NoError(t, err)
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return c
+}
+
+func (c *wsClient) writeFrame(fin bool, op byte, payload []byte, masked bool) {
+	c.t.Helper()
+
+	head := []byte{op, 0}
+	if fin {
+		head[0] |= 0x80
+	}
+
+	switch l := len(payload); {
+	case l <= 125:
+		head[1] = byte(l)
+	case l <= 0xffff:
+		head[1] = 126
+		head = binary.BigEndian.AppendUint16(head, uint16(l))
+	default:
+		head[1] = 127
+		head = binary.BigEndian.AppendUint64(head, uint64(l))
+	}
+
+	data := append([]byte(nil), payload...)
+	if masked {
+		head[1] |= 0x80
+		mask := []byte{1, 2, 3, 4}
+		head = append(head, mask...)
+		for i := range data {
+			data[i] ^= mask[i&3]
+		}
+	}
+
+	_, err := c.conn.Write(append(head, data...))
+	require.NoError(c.t, err)
+}
+
+func (c *wsClient) send(op byte, payload string) {
+	c.writeFrame(true, op, []byte(payload), true)
+}
+
+func (c *wsClient) sendClose(code CloseCode) {
+	c.send(opClose, string(binary.BigEndian.AppendUint16(nil, uint16(code))))
+}
+
+func (c *wsClient) readFrame() (byte, []byte) {
+	c.t.Helper()
+
+	var head [2]byte
+	_, err := io.ReadFull(c.br, head[:])
+	require.NoError(c.t, err)
+
+	length := int(head[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(c.br, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(c.br, ext[:])
+		length = int(binary.BigEndian.Uint64(ext[:]))
+	}
+
+	payload := make([]byte, length)
+	_, err = io.ReadFull(c.br, payload)
+	require.NoError(c.t, err)
+	return head[0] & 0x0f, payload
+}
+
+func (c *wsClient) readClose() CloseCode {
+	c.t.Helper()
+
+	op, payload := c.readFrame()
+	require.Equal(c.t, byte(opClose), op)
+	require.GreaterOrEqual(c.t, len(payload), 2)
+	return CloseCode(binary.BigEndian.Uint16(payload))
+}
+
+func webSocketServer(t *testing.T, opts WebSocketOptions, handler func(ws *WebSocket)) (*Application, *httptest.Server) {
+	t.Helper()
+
+	a, err := NewTestApplication(Options{})
+	require.NoError(t, err)
+
+	a.routes.Get("/ws", func(wctx *WebContext) {
+		ws, err := wctx.UpgradeWebSocket(opts)
+		if err != nil {
+			wctx.RenderError(err)
+			return
+		}
+		handler(ws)
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		RouteRequest(a, r, w)
+	}))
+	t.Cleanup(srv.Close)
+
+	return a, srv
+}
+
+func echo(ws *WebSocket) {
+	for {
+		typ, msg, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		ws.WriteMessage(typ, msg)
+	}
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	t.Run("it should compute the accept key from the RFC example", func(t *testing.T) {
+		assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+	})
+
+	t.Run("it should upgrade and negotiate a subprotocol", func(t *testing.T) {
+		_, srv := webSocketServer(t, WebSocketOptions{Subprotocols: []string{"v2", "v1"}}, echo)
+
+		c := dialWebSocket(t, srv, "/ws", map[string]string{"Sec-WebSocket-Protocol": "v1, v2"})
+
+		assert.Equal(t, http.StatusSwitchingProtocols, c.resp.StatusCode)
+		assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", c.resp.Header.Get("Sec-WebSocket-Accept"))
+		assert.Equal(t, "v2", c.resp.Header.Get("Sec-WebSocket-Protocol"))
+	})
+
+	t.Run("it should reject bad handshakes", func(t *testing.T) {
+		_, srv := webSocketServer(t, WebSocketOptions{}, echo)
+
+		resp, err := http.Get(srv.URL + "/ws")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		c := dialWebSocket(t, srv, "/ws", map[string]string{"Sec-WebSocket-Version": "8"})
+		assert.Equal(t, http.StatusUpgradeRequired, c.resp.StatusCode)
+		assert.Equal(t, "13", c.resp.Header.Get("Sec-WebSocket-Version"))
+
+		c = dialWebSocket(t, srv, "/ws", map[string]string{"Origin": "https://evil.example"})
+		assert.Equal(t, http.StatusForbidden, c.resp.StatusCode)
+	})
+}
+
+func TestWebSocketMessages(t *testing.T) {
+	t.Run("it should echo messages and reassemble fragments around pings", func(t *testing.T) {
+		_, srv := webSocketServer(t, WebSocketOptions{}, echo)
+		c := dialWebSocket(t, srv, "/ws", nil)
+
+		c.send(opText, "hello")
+		op, payload := c.readFrame()
+		assert.Equal(t, byte(opText), op)
+		assert.Equal(t, "hello", string(payload))
+
+		c.writeFrame(false, opBinary, []byte("ab"), true)
+		c.send(opPing, "are you there")
+		c.writeFrame(true, opContinuation, []byte("cd"), true)
+
+		op, payload = c.readFrame()
+		assert.Equal(t, byte(opPong), op)
+		assert.Equal(t, "are you there", string(payload))
+
+		op, payload = c.readFrame()
+		assert.Equal(t, byte(opBinary), op)
+		assert.Equal(t, "abcd", string(payload))
+
+		big := strings.Repeat("x", 70000)
+		c.send(opText, big)
+		_, payload = c.readFrame()
+		assert.Equal(t, big, string(payload))
+	})
+
+	t.Run("it should echo the peer close and report it to the reader", func(t *testing.T) {
+		errs := make(chan error, 1)
+		_, srv := webSocketServer(t, WebSocketOptions{}, func(ws *WebSocket) {
+			_, _, err := ws.ReadMessage()
+			errs <- err
+		})
+		c := dialWebSocket(t, srv, "/ws", nil)
+
+		c.sendClose(4001)
+		assert.Equal(t, CloseCode(4001), c.readClose())
+
+		err := <-errs
+		var ce *CloseError
+		require.True(t, errors.As(err, &ce))
+		assert.Equal(t, CloseCode(4001), ce.Code)
+		assert.ErrorIs(t, err, ErrWebSocketClosed)
+	})
+
+	t.Run("it should close with 1009 past MaxMessageSize", func(t *testing.T) {
+		errs := make(chan error, 1)
+		_, srv := webSocketServer(t, WebSocketOptions{MaxMessageSize: 8}, func(ws *WebSocket) {
+			_, _, err := ws.ReadMessage()
+			errs <- err
+		})
+		c := dialWebSocket(t, srv, "/ws", nil)
+
+		c.writeFrame(false, opText, []byte("12345"), true)
+		c.writeFrame(true, opContinuation, []byte("6789"), true)
+
+		assert.Equal(t, CloseMessageTooBig, c.readClose())
+		assert.ErrorIs(t, <-errs, ErrMessageTooLarge)
+	})
+
+	t.Run("it should close with 1002 on protocol violations", func(t *testing.T) {
+		violations := map[string]func(c *wsClient){
+			"unmasked":     func(c *wsClient) { c.writeFrame(true, opText, []byte("hi"), false) },
+			"continuation": func(c *wsClient) { c.writeFrame(true, opContinuation, []byte("hi"), true) },
+			"opcode":       func(c *wsClient) { c.send(0x3, "") },
+			"close code":   func(c *wsClient) { c.sendClose(1005) },
+		}
+
+		for name, violate := range violations {
+			errs := make(chan error, 1)
+			_, srv := webSocketServer(t, WebSocketOptions{}, func(ws *WebSocket) {
+				_, _, err := ws.ReadMessage()
+				errs <- err
+			})
+			c := dialWebSocket(t, srv, "/ws", nil)
+
+			violate(c)
+			assert.Equal(t, CloseProtocolError, c.readClose(), name)
+			assert.ErrorIs(t, <-errs, ErrWebSocketProtocol, name)
+		}
+	})
+
+	t.Run("it should close with 1007 on invalid utf-8 text", func(t *testing.T) {
+		_, srv := webSocketServer(t, WebSocketOptions{}, echo)
+		c := dialWebSocket(t, srv, "/ws", nil)
+
+		c.send(opText, "\xff\xfe")
+		assert.Equal(t, CloseInvalidPayload, c.readClose())
+	})
+
+	t.Run("it should ping on the interval", func(t *testing.T) {
+		_, srv := webSocketServer(t, WebSocketOptions{PingInterval: 20 * time.Millisecond}, echo)
+		c := dialWebSocket(t, srv, "/ws", nil)
+
+		op, _ := c.readFrame()
+		assert.Equal(t, byte(opPing), op)
+	})
+}
+
+func TestWebSocketLifecycle(t *testing.T) {
+	t.Run("it should close when the handler returns", func(t *testing.T) {
+		var ctx *Context
+		_, srv := webSocketServer(t, WebSocketOptions{CloseTimeout: time.Second}, func(ws *WebSocket) {
+			ctx = ws.Context()
+		})
+		c := dialWebSocket(t, srv, "/ws", nil)
+
+		assert.Equal(t, CloseNormal, c.readClose())
+		c.sendClose(CloseNormal)
+
+		require.NotNil(t, ctx)
+		assert.Eventually(t, func() bool { return ctx.Err() != nil }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("it should send going-away and drain on CloseWebSockets", func(t *testing.T) {
+		errs := make(chan error, 1)
+		a, srv := webSocketServer(t, WebSocketOptions{}, func(ws *WebSocket) {
+			_, _, err := ws.ReadMessage()
+			errs <- err
+		})
+		c := dialWebSocket(t, srv, "/ws", nil)
+		require.Eventually(t, func() bool { return a.websockets.count() == 1 }, time.Second, 5*time.Millisecond)
+
+		closed := make(chan error, 1)
+		go func() { closed <- a.CloseWebSockets(context.Background()) }()
+
+		assert.Equal(t, CloseGoingAway, c.readClose())
+		c.sendClose(CloseGoingAway)
+
+		assert.NoError(t, <-closed)
+		assert.ErrorIs(t, <-errs, ErrWebSocketClosed)
+		assert.Zero(t, a.websockets.count())
+
+		// Still refusing upgrades once the drain is over
+		late := dialWebSocket(t, srv, "/ws", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, late.resp.StatusCode)
+		assert.Zero(t, a.websockets.count())
+	})
+
+	t.Run("it should drop peers that never answer once ctx is done", func(t *testing.T) {
+		a, srv := webSocketServer(t, WebSocketOptions{CloseTimeout: time.Minute}, func(ws *WebSocket) {
+			<-ws.Context().Done()
+		})
+		c := dialWebSocket(t, srv, "/ws", nil)
+		require.Eventually(t, func() bool { return a.websockets.count() == 1 }, time.Second, 5*time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, a.CloseWebSockets(ctx), context.DeadlineExceeded)
+		assert.Equal(t, CloseGoingAway, c.readClose())
+		assert.Zero(t, a.websockets.count())
+	})
+}
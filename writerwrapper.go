@@ -122,10 +122,17 @@ func (u *UniversalResponseWriter) Flush() {
 
 // Hijack implements http.Hijacker
 func (u *UniversalResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	if h, ok := u.ResponseWriter.(http.Hijacker); ok {
-		return h.Hijack()
+	h, ok := u.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
 	}
-	return nil, nil, http.ErrNotSupported
+
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		// The handler owns the connection now; report it as upgraded
+		u.code, u.wroteHeader = http.StatusSwitchingProtocols, true
+	}
+	return conn, rw, err
 }
 
 // Push implements http.Pusher
@@ -136,12 +143,12 @@ func (u *UniversalResponseWriter) Push(target string, opts *http.PushOptions) er
 	return http.ErrNotSupported
 }
 
-// ReadFrom implements io.ReaderFrom
+// ReadFrom implements io.ReaderFrom. Teed or discarded responses go through
+// Write (which counts the bytes); otherwise the underlying ReadFrom is used
+// so net/http can sendfile.
 func (u *UniversalResponseWriter) ReadFrom(r io.Reader) (int64, error) {
-	if u.tee != nil {
-		n, err := io.Copy(&u.basicWriter, r)
-		u.bytes += int(n)
-		return n, err
+	if u.tee != nil || u.discard {
+		return io.Copy(&u.basicWriter, r)
 	}
 	if rf, ok := u.ResponseWriter.(io.ReaderFrom); ok {
 		u.maybeWriteHeader()
@@ -138,6 +138,35 @@ func TestHttpFancyWriter(t *testing.T) {
 	}
 }
 
+// TestReadFromWriter verifies ReadFrom counts bytes once and honours tee and discard.
+func TestReadFromWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	fw := &UniversalResponseWriter{basicWriter: basicWriter{ResponseWriter: &ReaderFromResponseWriter{rec}}}
+
+	var buf bytes.Buffer
+	fw.Tee(&buf)
+
+	n, err := fw.ReadFrom(bytes.NewReader([]byte("ReadFrom")))
+	if err != nil || n != 8 {
+		t.Errorf("unexpected ReadFrom result: %d, %v", n, err)
+	}
+	if fw.BytesWritten() != 8 {
+		t.Errorf("expected 8 bytes written, got %d", fw.BytesWritten())
+	}
+	if buf.String() != "ReadFrom" || rec.Body.String() != "ReadFrom" {
+		t.Errorf("unexpected tee %q or body %q", buf.String(), rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	fw = &UniversalResponseWriter{basicWriter: basicWriter{ResponseWriter: &ReaderFromResponseWriter{rec}}}
+	fw.Discard()
+
+	_, _ = fw.ReadFrom(bytes.NewReader([]byte("Discarded")))
+	if rec.Body.String() != "" {
+		t.Errorf("expected no response body, got %s", rec.Body.String())
+	}
+}
+
 func TestHijackWriter(t *testing.T) {
 	hijackableRec := &HijackableResponseWriter{ResponseWriter: httptest.NewRecorder()}
 	hw := &UniversalResponseWriter{basicWriter: basicWriter{ResponseWriter: hijackableRec}}